@@ -0,0 +1,118 @@
+package flow
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// BatchResult results of a single input set of RunBatch
+type BatchResult struct {
+	Index   int // index of the input set
+	Results []Data
+	Err     error
+}
+
+// UseBatchWorkers sets the number of sessions RunBatch runs at once,
+// defaults to the number of CPUs
+func (f *Flow) UseBatchWorkers(n int) *Flow {
+	f.batchWorkers = n
+	return f
+}
+
+// RunBatch runs ops in a new session for each input set, consts and pure
+// operations that don't depend on inputs or vars run once and are shared by
+// the whole batch. Results are sent in completion order and the channel is
+// closed once every input set is done, progress is reported to Progress
+// hooks. Once ctx is done input sets not yet started are skipped and the
+// channel is closed when the running ones finish
+func (f *Flow) RunBatch(ctx context.Context, inputs [][]Data, ops ...Operation) <-chan BatchResult {
+	oplist := make([]*operation, len(ops))
+	for i, op := range ops {
+		oplist[i] = op.(*operation)
+	}
+	shared := f.NewSession()
+	sharedOps := batchShared(oplist)
+
+	workers := f.batchWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	ret := make(chan BatchResult, workers)
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range inputs {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	done, failed := 0, 0
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				s := f.NewSession()
+				s.Inputs(inputs[i]...)
+				s.shared, s.sharedOps = shared, sharedOps
+				res, err := s.Run(ops...)
+
+				select {
+				case ret <- BatchResult{Index: i, Results: res, Err: err}:
+				case <-ctx.Done():
+					continue
+				}
+
+				mu.Lock()
+				done++
+				if err != nil {
+					failed++
+				}
+				f.hooks.progress(done, failed, len(inputs))
+				mu.Unlock()
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(ret)
+	}()
+	return ret
+}
+
+// batchShared returns the consts and pure operations that don't depend on
+// session inputs or vars, their results are the same for every session in
+// a batch. Impure operations run in every session
+func batchShared(roots []*operation) map[*operation]bool {
+	shared := map[*operation]bool{}
+	walk(roots, func(op *operation) error {
+		switch {
+		case op.kind == "const":
+		case op.kind == "alias", op.pure():
+			for _, in := range op.inputs {
+				if !shared[in] {
+					return nil
+				}
+			}
+		default:
+			return nil
+		}
+		shared[op] = true
+		return nil
+	})
+	return shared
+}
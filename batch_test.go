@@ -0,0 +1,108 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestRunBatch(t *testing.T) {
+	a := assert.A(t)
+
+	var calls int32
+	r := registry.New()
+	r.Add("expensive", func() int {
+		atomic.AddInt32(&calls, 1)
+		return 10
+	}).Pure()
+	r.Add("div", func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return a / b, nil
+	})
+
+	f := flow.New().UseRegistry(r).UseBatchWorkers(3)
+	var lastDone, lastFailed, total int
+	f.Hook(flow.Hook{
+		Progress: func(done, failed, n int, triggerTime time.Time) {
+			lastDone, lastFailed, total = done, failed, n
+		},
+	})
+
+	op := f.Op("div", f.Op("expensive"), f.In(0))
+
+	inputs := [][]flow.Data{{1}, {2}, {0}, {5}, {10}}
+	res := map[int]flow.BatchResult{}
+	for r := range f.RunBatch(context.Background(), inputs, op) {
+		res[r.Index] = r
+	}
+
+	a.Eq(len(res), len(inputs), "should have a result for every input set")
+	a.Eq(res[0].Results, []flow.Data{10}, "should divide by the input")
+	a.Eq(res[3].Results, []flow.Data{2}, "should divide by the input")
+	a.NotEq(res[2].Err, nil, "should report the item error")
+	a.Eq(res[4].Err, nil, "other items should not fail")
+	a.Eq(atomic.LoadInt32(&calls), int32(1), "input independent op should run once")
+	a.Eq([]int{lastDone, lastFailed, total}, []int{5, 1, 5}, "should report progress")
+}
+
+func TestRunBatchEmpty(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+	n := 0
+	for range f.RunBatch(context.Background(), nil, f.In(0)) {
+		n++
+	}
+	a.Eq(n, 0, "should close without results")
+}
+
+func TestRunBatchImpure(t *testing.T) {
+	a := assert.A(t)
+
+	var calls int32
+	r := registry.New()
+	r.Add("tick", func() int { return int(atomic.AddInt32(&calls, 1)) })
+	r.Add("add", func(a, b int) int { return a + b }).Pure()
+
+	f := flow.New().UseRegistry(r)
+	op := f.Op("add", f.Op("tick"), f.In(0))
+
+	inputs := [][]flow.Data{{1}, {2}, {3}}
+	for r := range f.RunBatch(context.Background(), inputs, op) {
+		a.Eq(r.Err, nil, "should not error")
+	}
+	a.Eq(atomic.LoadInt32(&calls), int32(len(inputs)), "impure op should run for every input set")
+}
+
+func TestRunBatchCancel(t *testing.T) {
+	a := assert.A(t)
+
+	r := registry.New()
+	r.Add("slow", func(a int) int {
+		time.Sleep(10 * time.Millisecond)
+		return a
+	})
+	f := flow.New().UseRegistry(r).UseBatchWorkers(1)
+
+	inputs := make([][]flow.Data, 100)
+	for i := range inputs {
+		inputs[i] = []flow.Data{i}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	for range f.RunBatch(ctx, inputs, f.Op("slow", f.In(0))) {
+		n++
+		if n == 2 {
+			cancel()
+		}
+	}
+	a.Eq(n < len(inputs), true, "should skip input sets after cancel")
+}
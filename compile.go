@@ -0,0 +1,451 @@
+package flow
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// Operators registry entries tried in order for each expression operator,
+// the first registered entry accepting the operand types is used, "neg" is
+// the unary minus
+var Operators = map[string][]string{
+	"+":   {"add", "matAdd", "vecadd"},
+	"-":   {"sub", "matSub", "vecsub"},
+	"*":   {"mul", "matMulElem", "vecmul"},
+	"/":   {"div", "vecdiv"},
+	"neg": {"neg"},
+}
+
+// CompileError parse or type error in an expression
+type CompileError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Compile builds the operations of an arithmetic expression like
+//
+//	sigmoid(matMul(w, x) + b)
+//
+// calls are registry entries, names can be qualified and versioned like
+// ml.sigmoid@2, identifiers are resolved against bindings
+// (operations or values), named inputs and vars in that order. Numbers are
+// untyped and converted to the type expected by the entry
+func Compile(f *Flow, src string, bindings map[string]Data) (Operation, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{f: f, src: src, toks: toks, bindings: bindings}
+	n := len(f.operations)
+	op, err := c.compile()
+	if err != nil {
+		// drop the operations built before the error
+		f.operations = f.operations[:n]
+		return nil, err
+	}
+	return op, nil
+}
+
+func (c *compiler) compile() (*operation, error) {
+	v, err := c.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := c.peek(); t.kind != tokEOF {
+		return nil, c.errorf(t.pos, "unexpected %s", t)
+	}
+	return c.materialize(v, nil)
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokPunct
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lex(src string) ([]token, error) {
+	toks := []token{}
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.ContainsRune("+-*/(),", c):
+			toks = append(toks, token{tokPunct, string(c), i})
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.' ||
+				(src[j] == 'e' || src[j] == 'E') ||
+				(src[j] == '-' || src[j] == '+') && (src[j-1] == 'e' || src[j-1] == 'E')) {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j], i})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, newCompileError(src, i, "unterminated string")
+			}
+			toks = append(toks, token{tokString, src[i : j+1], i})
+			i = j + 1
+		case isLetter(src[i]):
+			// qualified names like ml.sq and versions like sq@2
+			j := i
+			for j < len(src) && (isLetter(src[j]) || isDigit(src[j]) ||
+				src[j] == '.' && j+1 < len(src) && isLetter(src[j+1])) {
+				j++
+			}
+			if j+1 < len(src) && src[j] == '@' && isDigit(src[j+1]) {
+				for j++; j < len(src) && isDigit(src[j]); j++ {
+				}
+			}
+			toks = append(toks, token{tokIdent, src[i:j], i})
+			i = j
+		default:
+			return nil, newCompileError(src, i, fmt.Sprintf("unexpected character %q", c))
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+func newCompileError(src string, pos int, msg string) *CompileError {
+	line := 1 + strings.Count(src[:pos], "\n")
+	col := pos - strings.LastIndex(src[:pos], "\n")
+	return &CompileError{line, col, msg}
+}
+
+// value a compiled sub expression, untyped number literals are kept until
+// the expected type is known
+type value struct {
+	op  *operation
+	typ reflect.Type // nil if unknown
+	lit Data         // untyped number
+	pos int
+}
+
+type compiler struct {
+	f        *Flow
+	src      string
+	toks     []token
+	i        int
+	bindings map[string]Data
+}
+
+func (c *compiler) peek() token { return c.toks[c.i] }
+func (c *compiler) next() token {
+	t := c.toks[c.i]
+	if t.kind != tokEOF {
+		c.i++
+	}
+	return t
+}
+
+func (c *compiler) errorf(pos int, format string, args ...interface{}) error {
+	return newCompileError(c.src, pos, fmt.Sprintf(format, args...))
+}
+
+// expr: term (('+' | '-') term)*
+func (c *compiler) expr() (value, error) {
+	left, err := c.term()
+	if err != nil {
+		return value{}, err
+	}
+	for t := c.peek(); t.text == "+" || t.text == "-"; t = c.peek() {
+		c.next()
+		right, err := c.term()
+		if err != nil {
+			return value{}, err
+		}
+		if left, err = c.operator(t, left, right); err != nil {
+			return value{}, err
+		}
+	}
+	return left, nil
+}
+
+// term: unary (('*' | '/') unary)*
+func (c *compiler) term() (value, error) {
+	left, err := c.unary()
+	if err != nil {
+		return value{}, err
+	}
+	for t := c.peek(); t.text == "*" || t.text == "/"; t = c.peek() {
+		c.next()
+		right, err := c.unary()
+		if err != nil {
+			return value{}, err
+		}
+		if left, err = c.operator(t, left, right); err != nil {
+			return value{}, err
+		}
+	}
+	return left, nil
+}
+
+// unary: '-' unary | primary
+func (c *compiler) unary() (value, error) {
+	t := c.peek()
+	if t.text != "-" || t.kind != tokPunct {
+		return c.primary()
+	}
+	c.next()
+	v, err := c.unary()
+	if err != nil {
+		return value{}, err
+	}
+	switch n := v.lit.(type) {
+	case int:
+		return value{lit: -n, pos: t.pos}, nil
+	case float64:
+		return value{lit: -n, pos: t.pos}, nil
+	}
+	return c.operator(token{tokPunct, "neg", t.pos}, v)
+}
+
+// primary: number | string | ident | ident '(' args ')' | '(' expr ')'
+func (c *compiler) primary() (value, error) {
+	t := c.next()
+	switch t.kind {
+	case tokNumber:
+		if n, err := strconv.Atoi(t.text); err == nil {
+			return value{lit: n, pos: t.pos}, nil
+		}
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return value{}, c.errorf(t.pos, "invalid number %s", t)
+		}
+		return value{lit: n, pos: t.pos}, nil
+	case tokString:
+		s, err := strconv.Unquote(t.text)
+		if err != nil {
+			return value{}, c.errorf(t.pos, "invalid string %s", t)
+		}
+		return c.constValue(s, t.pos), nil
+	case tokIdent:
+		if c.peek().text == "(" {
+			return c.call(t)
+		}
+		return c.ident(t)
+	case tokPunct:
+		if t.text == "(" {
+			v, err := c.expr()
+			if err != nil {
+				return value{}, err
+			}
+			if end := c.next(); end.text != ")" {
+				return value{}, c.errorf(end.pos, "expected ')', found %s", end)
+			}
+			return v, nil
+		}
+	}
+	return value{}, c.errorf(t.pos, "unexpected %s", t)
+}
+
+func (c *compiler) call(name token) (value, error) {
+	c.next() // (
+	args := []value{}
+	if c.peek().text != ")" {
+		for {
+			v, err := c.expr()
+			if err != nil {
+				return value{}, err
+			}
+			args = append(args, v)
+			if c.peek().text != "," {
+				break
+			}
+			c.next()
+		}
+	}
+	if end := c.next(); end.text != ")" {
+		return value{}, c.errorf(end.pos, "expected ')' or ',', found %s", end)
+	}
+	e, err := c.f.registry.Entry(name.text)
+	if err != nil {
+		return value{}, c.errorf(name.pos, "unknown function %q", name.text)
+	}
+	return c.apply(name.text, name.pos, e.Inputs, e.Output, args)
+}
+
+// operator resolves the entry for the operator token
+func (c *compiler) operator(t token, args ...value) (value, error) {
+	for _, name := range Operators[t.text] {
+		e, err := c.f.registry.Entry(name)
+		if err != nil || len(e.Inputs) != len(args) {
+			continue
+		}
+		ok := true
+		for i, a := range args {
+			ok = ok && c.accepts(a, e.Inputs[i])
+		}
+		if ok {
+			return c.apply(name, t.pos, e.Inputs, e.Output, args)
+		}
+	}
+	types := make([]string, len(args))
+	for i, a := range args {
+		types[i] = typeName(a)
+	}
+	op := t.text
+	if op == "neg" {
+		op = "-"
+	}
+	return value{}, c.errorf(t.pos, "no entry for operator %s with %s", op, strings.Join(types, ", "))
+}
+
+// apply creates the operation for the entry checking argument types
+func (c *compiler) apply(name string, pos int, inputs []reflect.Type, output reflect.Type, args []value) (value, error) {
+	variadic := false
+	if fn, err := c.f.registry.Get(name); err == nil {
+		variadic = reflect.TypeOf(fn).IsVariadic()
+	}
+	if variadic && len(args) < len(inputs)-1 || !variadic && len(args) != len(inputs) {
+		return value{}, c.errorf(pos, "%s expects %d arguments, got %d", name, len(inputs), len(args))
+	}
+	params := make([]Data, len(args))
+	for i, a := range args {
+		in := inputs[len(inputs)-1]
+		if i < len(inputs)-1 || !variadic {
+			in = inputs[i]
+		} else {
+			in = in.Elem()
+		}
+		op, err := c.materialize(a, in)
+		if err != nil {
+			return value{}, err
+		}
+		params[i] = op
+	}
+	op := c.f.Op(name, params...).(*operation)
+	return value{op: op, typ: output, pos: pos}, nil
+}
+
+func (c *compiler) ident(t token) (value, error) {
+	if b, ok := c.bindings[t.text]; ok {
+		if op, ok := b.(*operation); ok {
+			return value{op: op, typ: op.outputType(), pos: t.pos}, nil
+		}
+		return c.constValue(b, t.pos), nil
+	}
+	for _, in := range c.f.inputs {
+		if in.name == t.text {
+			return value{op: in, typ: in.param.Type, pos: t.pos}, nil
+		}
+	}
+	for _, op := range c.f.operations {
+		if op.kind == "var" && op.name == t.text {
+			return value{op: op, pos: t.pos}, nil
+		}
+	}
+	if _, ok := c.f.Data.Load(t.text); ok {
+		return value{op: c.f.Var(t.text, nil).(*operation), pos: t.pos}, nil
+	}
+	if _, err := c.f.registry.Entry(t.text); err == nil {
+		return value{}, c.errorf(t.pos, "%s is a function, missing call", t.text)
+	}
+	return value{}, c.errorf(t.pos, "undefined %s", t.text)
+}
+
+func (c *compiler) constValue(v Data, pos int) value {
+	return value{op: c.f.Const(v).(*operation), typ: reflect.TypeOf(v), pos: pos}
+}
+
+// accepts checks if v can be used where typ is expected
+func (c *compiler) accepts(v value, typ reflect.Type) bool {
+	if v.lit != nil {
+		_, err := convertLiteral(v.lit, typ)
+		return err == nil
+	}
+	if v.typ == nil || typ == nil {
+		return true
+	}
+	return v.typ.AssignableTo(typ)
+}
+
+// materialize returns the operation for v as an input of type typ
+func (c *compiler) materialize(v value, typ reflect.Type) (*operation, error) {
+	if v.lit != nil {
+		lit, err := convertLiteral(v.lit, typ)
+		if err != nil {
+			return nil, c.errorf(v.pos, "%v", err)
+		}
+		return c.f.Const(lit).(*operation), nil
+	}
+	if !c.accepts(v, typ) {
+		return nil, c.errorf(v.pos, "cannot use %s as %v", typeName(v), typ)
+	}
+	return v.op, nil
+}
+
+// convertLiteral converts an untyped number to typ, the default type is
+// kept if typ is not numeric
+func convertLiteral(lit Data, typ reflect.Type) (Data, error) {
+	if typ == nil {
+		return lit, nil
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f, ok := lit.(float64); ok && f != math.Trunc(f) {
+			return nil, fmt.Errorf("constant %v truncated to %v", f, typ)
+		}
+		fallthrough
+	case reflect.Float32, reflect.Float64:
+		v, err := registry.ConvertNumber(reflect.ValueOf(lit), typ)
+		if err != nil {
+			return nil, fmt.Errorf("constant %v", err)
+		}
+		return v.Interface(), nil
+	}
+	if reflect.TypeOf(lit).AssignableTo(typ) {
+		return lit, nil
+	}
+	return nil, fmt.Errorf("cannot use %v as %v", lit, typ)
+}
+
+func typeName(v value) string {
+	if v.lit != nil {
+		return "untyped number"
+	}
+	if v.typ == nil {
+		return "any"
+	}
+	return v.typ.String()
+}
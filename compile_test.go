@@ -0,0 +1,134 @@
+package flow_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestCompile(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b float64) float64 { return a + b })
+	r.Add("mul", func(a, b float64) float64 { return a * b })
+	r.Add("neg", func(a float64) float64 { return -a })
+	r.Add("sigmoid", func(a float64) float64 { return 1 / (1 + math.Exp(-a)) })
+	r.Add("vecadd", VecAdd)
+	r.Add("repeat", func(s string, n int) string {
+		ret := ""
+		for i := 0; i < n; i++ {
+			ret += s
+		}
+		return ret
+	})
+	f := flow.New().UseRegistry(r)
+
+	f.Input("x", reflect.TypeOf(0.0), nil)
+	op, err := flow.Compile(f, "sigmoid(w * x + b)", map[string]flow.Data{
+		"w": 2.0,
+		"b": f.Const(-1.0),
+	})
+	a.Eq(err, nil, "should compile")
+
+	sess := f.NewSession()
+	sess.NamedInputs(map[string]flow.Data{"x": 0.5})
+	res, err := sess.Run(op)
+	a.Eq(err, nil, "should run")
+	a.Eq(res[0], 0.5, "should compute sigmoid(2*0.5-1)")
+
+	op, err = flow.Compile(f, "-(1 + 2) * 3", nil)
+	a.Eq(err, nil, "should compile untyped numbers")
+	v, _ := op.Process()
+	a.Eq(v, -9.0, "numbers should take the entry type")
+
+	op, err = flow.Compile(f, `repeat("ab", 2)`, nil)
+	a.Eq(err, nil, "should compile calls")
+	v, _ = op.Process()
+	a.Eq(v, "abab", "should convert to int param")
+
+	op, err = flow.Compile(f, "v + v", map[string]flow.Data{"v": []float32{1, 2}})
+	a.Eq(err, nil, "should resolve operator by type")
+	v, _ = op.Process()
+	a.Eq(v, []float32{2, 4}, "should use vecadd")
+
+	f.Var("counter", 3.0)
+	op, err = flow.Compile(f, "counter * 2", nil)
+	a.Eq(err, nil, "should resolve vars")
+	v, _ = op.Process()
+	a.Eq(v, 6.0, "should read the var")
+}
+
+func TestCompileErrors(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b float64) float64 { return a + b })
+	r.Add("sigmoid", func(a float64) float64 { return 1 / (1 + math.Exp(-a)) })
+	r.Add("byte", func(b uint8) uint8 { return b })
+	r.Add("repeat", func(s string, n int) string {
+		ret := ""
+		for i := 0; i < n; i++ {
+			ret += s
+		}
+		return ret
+	})
+	f := flow.New().UseRegistry(r)
+
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"1 +", "1:4: unexpected end of expression"},
+		{"add(1, 2", "1:9: expected ')' or ',', found end of expression"},
+		{"foo(1)", "1:1: unknown function \"foo\""},
+		{"1 + y", "1:5: undefined y"},
+		{"add(1)", "1:1: add expects 2 arguments, got 1"},
+		{`add(1, "a")`, "1:8: cannot use string as float64"},
+		{`repeat("a", 1.5)`, "1:13: constant 1.5 truncated to int"},
+		{`"a" + "b"`, "1:5: no entry for operator + with string, string"},
+		{"sigmoid", "1:1: sigmoid is a function, missing call"},
+		{"1 $ 2", "1:3: unexpected character '$'"},
+		{"byte(300)", "1:6: constant 300 does not fit in uint8"},
+		{"byte(-1)", "1:6: constant -1 does not fit in uint8"},
+	}
+	for _, tt := range tests {
+		_, err := flow.Compile(f, tt.src, nil)
+		a.NotEq(err, nil, "should error: "+tt.src)
+		if err != nil {
+			a.Eq(err.Error(), tt.err, "error position: "+tt.src)
+		}
+		a.Eq(len(f.Operations()), 0, "should not leave operations: "+tt.src)
+	}
+	_, err := flow.Compile(f, "1 +\n  x", nil)
+	ce, ok := err.(*flow.CompileError)
+	a.Eq(ok, true, "should be a CompileError")
+	a.Eq([]int{ce.Line, ce.Col}, []int{2, 3}, "should report line and column")
+}
+
+func TestCompileQualified(t *testing.T) {
+	a := assert.A(t)
+	ml := registry.New().UseNamespace("ml")
+	ml.Add("sq", func(a float64) float64 { return a * a })
+	ml.Add("sq@2", func(a, b float64) float64 { return a * b })
+	r := registry.New()
+	r.Add("sq", func(a float64) float64 { return -a })
+	r.Add("add", func(a, b float64) float64 { return a + b })
+	r.Merge(ml)
+	f := flow.New().UseRegistry(r)
+
+	op, err := flow.Compile(f, "ml.sq@1(3)", nil)
+	a.Eq(err, nil, "should compile qualified names")
+	v, _ := op.Process()
+	a.Eq(v, 9.0, "should call the namespaced entry")
+
+	op, err = flow.Compile(f, "sq(2) + ml.sq@2(2, 3)", nil)
+	a.Eq(err, nil, "should compile versioned names")
+	v, _ = op.Process()
+	a.Eq(v, 4.0, "should call each entry")
+
+	_, err = flow.Compile(f, "ml.sq@3(1)", nil)
+	a.NotEq(err, nil, "should not find missing versions")
+}
@@ -0,0 +1,140 @@
+package flow_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestConcurrentSessions(t *testing.T) {
+	a := assert.A(t)
+
+	var running, maxRunning int32
+	r := registry.New()
+	r.Add("slowmul", func(a, b int) int {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return a * b
+	})
+	r.Add("add", func(a, b int) int { return a + b })
+
+	f := flow.New().UseRegistry(r)
+	x := f.In(0)
+	shared := f.Op("slowmul", x, x)
+	op := f.Op("add", shared, f.Op("slowmul", shared, 2))
+
+	const n = 20
+	var wg sync.WaitGroup
+	res := make([]flow.Data, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sess := f.NewSession()
+			sess.Inputs(i)
+			res[i], errs[i] = sess.Run(op)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		a.Eq(errs[i], nil, "session should not error")
+		a.Eq(res[i], []flow.Data{i*i + i*i*2}, "session should have its own result")
+	}
+	a.Eq(atomic.LoadInt32(&maxRunning) > 1, true, "sessions should run in parallel")
+}
+
+func TestConcurrentSessionShared(t *testing.T) {
+	a := assert.A(t)
+
+	var calls int32
+	r := registry.New()
+	r.Add("count", func() int {
+		time.Sleep(time.Millisecond)
+		return int(atomic.AddInt32(&calls, 1))
+	})
+	r.Add("add", func(a, b int) int { return a + b })
+
+	f := flow.New().UseRegistry(r)
+	c := f.Op("count")
+	op1 := f.Op("add", c, c)
+	op2 := f.Op("add", c, 1)
+
+	sess := f.NewSession()
+	var wg sync.WaitGroup
+	for _, op := range []flow.Operation{op1, op2, op1, op2} {
+		wg.Add(1)
+		go func(op flow.Operation) {
+			defer wg.Done()
+			_, err := sess.Run(op)
+			a.Eq(err, nil, "run should not error")
+		}(op)
+	}
+	wg.Wait()
+	a.Eq(atomic.LoadInt32(&calls), int32(1), "shared op should execute once per session")
+}
+
+type traceCollector struct {
+	sync.Mutex
+	traces [][]*flow.Span
+}
+
+func (c *traceCollector) Export(spans []*flow.Span) error {
+	c.Lock()
+	defer c.Unlock()
+	c.traces = append(c.traces, spans)
+	return nil
+}
+
+func TestConcurrentSessionTrace(t *testing.T) {
+	a := assert.A(t)
+
+	r := registry.New()
+	r.Add("add", func(a, b int) int {
+		time.Sleep(time.Millisecond)
+		return a + b
+	})
+	c := &traceCollector{}
+	f := flow.New().UseRegistry(r).UseExporter(c)
+
+	const n = 10
+	ops := make([]flow.Operation, n)
+	for i := range ops {
+		ops[i] = f.Op("add", i, n)
+	}
+	sess := f.NewSession()
+	var wg sync.WaitGroup
+	for _, op := range ops {
+		wg.Add(1)
+		go func(op flow.Operation) {
+			defer wg.Done()
+			_, err := sess.Run(op)
+			a.Eq(err, nil, "run should not error")
+		}(op)
+	}
+	wg.Wait()
+
+	a.Eq(len(c.traces), n, "each run should export its own trace")
+	ids := map[string]bool{}
+	for _, spans := range c.traces {
+		a.Eq(len(spans), 3, "trace should have the op and its inputs")
+		for _, s := range spans {
+			a.Eq(s.TraceID, spans[0].TraceID, "spans of a run should share the trace")
+		}
+		ids[spans[0].TraceID] = true
+	}
+	a.Eq(len(ids), n, "runs should have different traces")
+}
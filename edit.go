@@ -0,0 +1,145 @@
+package flow
+
+import (
+	"fmt"
+)
+
+// Graph editing, every edit invalidates the cached results of the edited
+// operation and its dependents in existing sessions. Edits wait for running
+// sessions to finish, operations must not edit the flow running them.
+// Operations merged, folded or pruned by Optimize can only be removed
+
+// Remove an operation from the flow, operations using it must be rewired
+// first, the removed operation will error if processed
+func (f *Flow) Remove(op Operation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o := op.(*operation)
+	if len(f.dependents(o)) > 0 {
+		return ErrInUse
+	}
+	f.operations = removeOp(f.operations, o)
+	f.pruned = removeOp(f.pruned, o)
+	o.inputs = nil
+	o.executor = func(*Session, ...Data) (Data, error) { return nil, ErrOperation }
+	o.version++
+	return nil
+}
+
+// SetInput replaces input i of op with an operation or a const value
+func (f *Flow) SetInput(op Operation, i int, input Data) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o := op.(*operation)
+	if o.frozen {
+		return ErrOptimized
+	}
+	if i < 0 || i >= len(o.inputs) {
+		return ErrInput
+	}
+	in := f.makeInputs(input)[0]
+	// input must not depend on op
+	err := walk([]*operation{in}, func(dep *operation) error {
+		if dep == o {
+			return ErrLoop
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.inputs[i] = in
+	f.invalidate(o)
+	return nil
+}
+
+// SetEntry swaps the registry entry behind a func operation, inputs are
+// truncated or padded with nil consts to the new entry inputs
+func (f *Flow) SetEntry(op Operation, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o := op.(*operation)
+	if o.frozen {
+		return ErrOptimized
+	}
+	if o.kind != "func" {
+		return ErrOperation
+	}
+	entry, err := f.registry.Entry(name)
+	if err != nil {
+		return fmt.Errorf("%v '%s'", err, name)
+	}
+	registryFn, err := f.registry.Get(name)
+	if err != nil {
+		return err
+	}
+	nIn := len(entry.Inputs)
+	for len(o.inputs) < nIn {
+		o.inputs = append(o.inputs, f.makeInputs(nil)[0])
+	}
+	o.inputs = o.inputs[:nIn]
+	o.name = name
+	o.executor = makeExecutor(o, registryFn, entry)
+	f.invalidate(o)
+	return nil
+}
+
+// SetConst updates the value of a const operation
+func (f *Flow) SetConst(op Operation, value Data) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o := op.(*operation)
+	if o.frozen {
+		return ErrOptimized
+	}
+	if o.kind != "const" {
+		return ErrOperation
+	}
+	// consts with equal values share the slot, use a new one
+	f.consts = append(f.consts, value)
+	o.executor = func(*Session, ...Data) (Data, error) { return value, nil }
+	f.invalidate(o)
+	return nil
+}
+
+// invalidate bumps the version of op and every operation depending on it
+func (f *Flow) invalidate(op *operation) {
+	op.version++
+	for _, dep := range f.dependents(op) {
+		dep.version++
+	}
+}
+
+// dependents returns operations that use op directly or indirectly,
+// including operations pruned by Optimize
+func (f *Flow) dependents(op *operation) []*operation {
+	ret := []*operation{}
+	found := map[*operation]bool{op: true}
+	all := append(append([]*operation{}, f.operations...), f.pruned...)
+	for changed := true; changed; {
+		changed = false
+		for _, fo := range all {
+			if found[fo] {
+				continue
+			}
+			for _, in := range fo.inputs {
+				if found[in] {
+					found[fo] = true
+					ret = append(ret, fo)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func removeOp(ops []*operation, op *operation) []*operation {
+	for i, o := range ops {
+		if o == op {
+			return append(ops[:i], ops[i+1:]...)
+		}
+	}
+	return ops
+}
@@ -0,0 +1,179 @@
+package flow_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestEditConst(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	f := flow.New().UseRegistry(r)
+
+	c := f.Const(2)
+	other := f.Const(2)
+	add := f.Op("add", c, 3)
+
+	sess := f.NewSession()
+	res, err := sess.Run(add)
+	a.Eq(err, nil, "should not error")
+	a.Eq(res[0], 5, "2+3")
+
+	err = f.SetConst(c, 10)
+	a.Eq(err, nil, "should set const")
+	res, _ = sess.Run(add)
+	a.Eq(res[0], 13, "cached result should be invalidated")
+
+	v, _ := other.Process()
+	a.Eq(v, 2, "consts with the same value should not change")
+
+	a.Eq(f.SetConst(add, 1), flow.ErrOperation, "should only set consts")
+}
+
+func TestEditInput(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	r.Add("mul", func(a, b int) int { return a * b })
+	r.Add("neg", func(a int) int { return -a })
+	f := flow.New().UseRegistry(r)
+
+	add := f.Op("add", 1, 2)
+	neg := f.Op("neg", add)
+
+	sess := f.NewSession()
+	res, _ := sess.Run(neg)
+	a.Eq(res[0], -3, "-(1+2)")
+
+	err := f.SetInput(add, 1, f.Op("mul", 3, 3))
+	a.Eq(err, nil, "should rewire input")
+	res, _ = sess.Run(neg)
+	a.Eq(res[0], -10, "-(1+3*3)")
+
+	a.Eq(f.SetInput(add, 0, neg), flow.ErrLoop, "should not allow loops")
+	a.Eq(f.SetInput(add, 5, 1), flow.ErrInput, "should check input index")
+}
+
+func TestEditEntry(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	r.Add("mul", func(a, b int) int { return a * b })
+	r.Add("neg", func(a int) int { return -a })
+	f := flow.New().UseRegistry(r)
+
+	op := f.Op("add", 2, 3)
+	sess := f.NewSession()
+	res, _ := sess.Run(op)
+	a.Eq(res[0], 5, "2+3")
+
+	a.Eq(f.SetEntry(op, "mul"), nil, "should swap entry")
+	res, _ = sess.Run(op)
+	a.Eq(res[0], 6, "2*3")
+	a.Eq(op.Name(), "mul", "name should change")
+
+	a.Eq(f.SetEntry(op, "neg"), nil, "should swap to an entry with less inputs")
+	a.Eq(len(op.Inputs()), 1, "inputs should be truncated")
+	res, _ = sess.Run(op)
+	a.Eq(res[0], -2, "-2")
+
+	a.NotEq(f.SetEntry(op, "none"), nil, "should error with unknown entry")
+}
+
+func TestEditRemove(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	r.Add("neg", func(a int) int { return -a })
+	f := flow.New().UseRegistry(r)
+
+	add := f.Op("add", 1, 2)
+	neg := f.Op("neg", add)
+
+	a.Eq(f.Remove(add), flow.ErrInUse, "should not remove used op")
+	a.Eq(f.Remove(neg), nil, "should remove op")
+	for _, op := range f.Operations() {
+		a.NotEq(op, neg, "removed op should not be listed")
+	}
+	_, err := neg.Process()
+	a.Eq(err, flow.ErrOperation, "removed op should error")
+}
+
+func TestEditWhileRunning(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	f := flow.New().UseRegistry(r)
+
+	c := f.Const(1)
+	add := f.Op("add", c, 2)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := f.NewSession().Run(add)
+			a.Eq(err, nil, "run should not error")
+		}()
+		go func(i int) {
+			defer wg.Done()
+			f.SetConst(c, i)
+			f.SetInput(add, 1, i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEditPruned(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	r.Add("neg", func(a int) int { return -a })
+	f := flow.New().UseRegistry(r)
+
+	c := f.Const(1)
+	kept := f.Op("neg", c)
+	pruned := f.Op("add", c, 1)
+	f.Optimize(kept)
+
+	sess := f.NewSession()
+	res, _ := sess.Run(pruned)
+	a.Eq(res[0], 2, "1+1")
+	a.Eq(f.SetConst(c, 2), nil, "should set const")
+	res, _ = sess.Run(pruned)
+	a.Eq(res[0], 3, "pruned op should be invalidated")
+}
+
+func TestEditOptimized(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	r.Add("mul", func(a, b int) int { return a * b })
+	r.Add("neg", func(a int) int { return -a }).Pure()
+	f := flow.New().UseRegistry(r)
+
+	c := f.Const(2)
+	other := f.Const(2)
+	free := f.Const(3)
+	sum := f.Op("add", c, other)
+	folded := f.Op("neg", free)
+	res := f.Op("mul", sum, folded)
+	pruned := f.Op("add", f.In(0), 1)
+	f.Optimize(res)
+
+	a.Eq(f.SetConst(other, 10), flow.ErrOptimized, "should not edit a merged const")
+	a.Eq(f.SetConst(c, 10), flow.ErrOptimized, "should not edit a const others merged into")
+	a.Eq(f.SetConst(folded, 10), flow.ErrOptimized, "should not edit a folded op")
+	a.Eq(f.SetInput(pruned, 1, 2), flow.ErrOptimized, "should not edit a pruned op")
+	a.Eq(f.SetEntry(res, "add"), nil, "should edit kept ops")
+	a.Eq(sum.Inputs(), []flow.Operation{c, c}, "merged const should be rewired")
+
+	v, err := res.Process()
+	a.Eq(err, nil, "should not error")
+	a.Eq(v, 1, "(2+2)+-3")
+}
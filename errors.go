@@ -9,4 +9,7 @@ var (
 	ErrInput     = errors.New("invalid input")
 	ErrOutput    = errors.New("invalid output")
 	ErrOperation = errors.New("invalid operation")
+	ErrInUse     = errors.New("operation in use")
+	ErrLoop      = errors.New("operation loop")
+	ErrOptimized = errors.New("operation changed by Optimize")
 )
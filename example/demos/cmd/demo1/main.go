@@ -19,6 +19,8 @@ import (
 	"github.com/hexasoftware/flow/example/demos/ops/stringops"
 	"github.com/hexasoftware/flow/example/demos/ops/webops"
 	"github.com/hexasoftware/flow/flowserver"
+	"github.com/hexasoftware/flow/metrics"
+	"github.com/hexasoftware/flow/registry"
 )
 
 //go:generate go get github.com/gohxs/folder2go
@@ -36,19 +38,13 @@ func main() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", assetFunc)
 
-	defops := defaultops.New()
-	defops.Merge(genericops.New())
-	defops.Merge(stringops.New())
+	defops := mergeRegistries(defaultops.New(), genericops.New(), stringops.New())
 
 	mux.Handle("/default/", c.Build(
 		http.StripPrefix("/default", flowserver.New(defops, "default")),
 	))
 
-	mlReg := ml.New()
-	mlReg.Merge(genericops.New())
-	mlReg.Merge(stringops.New())
-	mlReg.Merge(webops.New())
-	mlReg.Merge(decodeops.New())
+	mlReg := mergeRegistries(ml.New(), genericops.New(), stringops.New(), webops.New(), decodeops.New())
 
 	mux.Handle("/machinelearning/", c.Build(
 		http.StripPrefix("/machinelearning", flowserver.New(mlReg, "ml")),
@@ -58,12 +54,26 @@ func main() {
 		http.StripPrefix("/devops", flowserver.New(devops.New(), "devops")),
 	))
 
+	mux.Handle("/metrics", metrics.Handler())
+
 	// Serve UI here
 
 	// Context registry
 	http.ListenAndServe(addr, mux)
 }
 
+// mergeRegistries merges namespaced registries in a new registry without
+// namespace, failing on name conflicts
+func mergeRegistries(regs ...*registry.R) *registry.R {
+	r := registry.New()
+	for _, or := range regs {
+		if err := r.MergeConflict(or, registry.ConflictError); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return r
+}
+
 func assetFunc(w http.ResponseWriter, r *http.Request) {
 	urlPath := ""
 
@@ -0,0 +1,303 @@
+// Command flow runs flow-ui documents without the websocket UI
+//
+//	flow run [-doc file | -store name -id ID] [-registry defaultops,...] [-in name=value]... [-stdin] [-optimize] [nodeID...]
+//
+// Nodes without outgoing links are built if no node IDs are given, inputs
+// are parsed as JSON falling back to strings, numeric names set positional
+// inputs. Results and per node timings are printed as JSON to stdout.
+//
+// Exit status is 0 on success, 1 if the flow fails and 2 if the document
+// can't be loaded or built.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/example/demos/ops/decodeops"
+	"github.com/hexasoftware/flow/example/demos/ops/defaultops"
+	"github.com/hexasoftware/flow/example/demos/ops/devops"
+	"github.com/hexasoftware/flow/example/demos/ops/genericops"
+	"github.com/hexasoftware/flow/example/demos/ops/ml"
+	"github.com/hexasoftware/flow/example/demos/ops/stringops"
+	"github.com/hexasoftware/flow/example/demos/ops/webops"
+	"github.com/hexasoftware/flow/flowserver"
+	"github.com/hexasoftware/flow/flowserver/flowbuilder"
+	"github.com/hexasoftware/flow/registry"
+)
+
+const (
+	exitOK    = 0
+	exitFail  = 1
+	exitUsage = 2
+)
+
+var registries = map[string]func() *registry.R{
+	"decodeops":  decodeops.New,
+	"defaultops": defaultops.New,
+	"devops":     devops.New,
+	"genericops": genericops.New,
+	"ml":         ml.New,
+	"stringops":  stringops.New,
+	"webops":     webops.New,
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "run" {
+		fmt.Fprintln(os.Stderr, "usage: flow run [flags] [nodeID...]")
+		os.Exit(exitUsage)
+	}
+	os.Exit(run(os.Args[2:], os.Stdin, os.Stdout))
+}
+
+// Report printed after a run
+type Report struct {
+	Results  map[string]interface{} `json:"results"`
+	Nodes    []*NodeTiming          `json:"nodes"`
+	Duration float64                `json:"durationMs"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// NodeTiming activity of a node during the run
+type NodeTiming struct {
+	ID       string    `json:"id"`
+	Src      string    `json:"src"`
+	Status   string    `json:"status"`
+	Start    time.Time `json:"start"`
+	Duration float64   `json:"durationMs"`
+	Error    string    `json:"error,omitempty"`
+}
+
+type inputFlag map[string]string
+
+func (i inputFlag) String() string { return fmt.Sprint(map[string]string(i)) }
+
+func (i inputFlag) Set(v string) error {
+	kv := strings.SplitN(v, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("expected name=value, got %q", v)
+	}
+	i[kv[0]] = kv[1]
+	return nil
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	docFile := fs.String("doc", "", "flow-ui document file")
+	store := fs.String("store", "default", "flowserver store to load -id from")
+	ID := fs.String("id", "", "document ID in the flowserver store")
+	regs := fs.String("registry", "defaultops,genericops,stringops", "comma separated registries: "+registryNames())
+	inputs := inputFlag{}
+	fs.Var(inputs, "in", "input name=value, can be repeated")
+	readStdin := fs.Bool("stdin", false, "read inputs as a JSON object from stdin")
+	verbose := fs.Bool("v", false, "log builder and flow messages to stderr")
+	optimize := fs.Bool("optimize", false, "merge and fold pure operations before running")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+	fail := func(err error) int {
+		fmt.Fprintln(os.Stderr, "flow run:", err)
+		return exitUsage
+	}
+
+	var rawDoc []byte
+	var err error
+	switch {
+	case *docFile != "":
+		rawDoc, err = ioutil.ReadFile(*docFile)
+	case *ID != "":
+		rawDoc, err = ioutil.ReadFile(flowserver.StorePath(*store, *ID))
+	default:
+		err = fmt.Errorf("one of -doc or -id is required")
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	r := registry.New()
+	for _, name := range strings.Split(*regs, ",") {
+		newReg, ok := registries[strings.TrimSpace(name)]
+		if !ok {
+			return fail(fmt.Errorf("unknown registry %q, available: %s", name, registryNames()))
+		}
+		if err := r.MergeConflict(newReg(), registry.ConflictError); err != nil {
+			return fail(err)
+		}
+	}
+	// entries the flowserver session adds
+	r.Add("Notify", func(v flow.Data, msg string) flow.Data {
+		fmt.Fprintln(os.Stderr, "Notify:", msg)
+		return v
+	})
+	r.Add("Log", func() io.Writer {
+		return os.Stderr
+	})
+	r.Add("Output", func(d interface{}) interface{} {
+		return d
+	})
+
+	named := map[string]flow.Data{}
+	if *readStdin {
+		if err := json.NewDecoder(stdin).Decode(&named); err != nil {
+			return fail(fmt.Errorf("stdin: %v", err))
+		}
+	}
+	for k, v := range inputs {
+		named[k] = parseInput(v)
+	}
+	positional := []flow.Data{}
+	for k, v := range named {
+		i, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		delete(named, k)
+		for len(positional) <= i {
+			positional = append(positional, nil)
+		}
+		positional[i] = v
+	}
+
+	builder := flowbuilder.New(r).Load(rawDoc)
+	if builder.Err != nil {
+		return fail(builder.Err)
+	}
+	for _, inc := range builder.Doc.Compat(r) {
+		fmt.Fprintln(os.Stderr, "flow run: warning:", inc)
+	}
+	IDs := fs.Args()
+	if len(IDs) == 0 {
+		IDs = builder.Doc.Sinks()
+	}
+	ops := make([]flow.Operation, len(IDs))
+	for i, ID := range IDs {
+		if builder.Doc.FetchNodeByID(ID) == nil {
+			return fail(fmt.Errorf("node not found [%v]", ID))
+		}
+		ops[i] = builder.Build(ID)
+	}
+	if builder.Err != nil {
+		return fail(builder.Err)
+	}
+	if *optimize {
+		builder.Optimize(IDs...)
+	}
+
+	report := &Report{Results: map[string]interface{}{}, Nodes: []*NodeTiming{}}
+	var mu sync.Mutex
+	timings := map[string]*NodeTiming{}
+	f := builder.Flow()
+	f.Hook(flow.Hook{
+		Any: func(name string, op flow.Operation, triggerTime time.Time, extra ...interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, nodeID := range builder.GetOpIDs(op) {
+				t, ok := timings[nodeID]
+				if !ok {
+					t = &NodeTiming{ID: nodeID, Src: builder.Doc.FetchNodeByID(nodeID).Src}
+					timings[nodeID] = t
+				}
+				switch name {
+				case "Wait":
+					t.Status = "waiting"
+				case "Start":
+					t.Status = "running"
+					t.Start = triggerTime
+				case "Finish":
+					t.Status = "finish"
+					t.Duration = since(t.Start, triggerTime)
+				case "Error":
+					t.Status = "error"
+					t.Duration = since(t.Start, triggerTime)
+					t.Error = fmt.Sprint(extra[0])
+				}
+			}
+		},
+	})
+
+	sess := f.NewSession()
+	sess.Inputs(positional...)
+	sess.NamedInputs(named)
+	start := time.Now()
+	res, err := sess.Run(ops...)
+	report.Duration = ms(time.Since(start))
+
+	code := exitOK
+	if err != nil {
+		report.Error = err.Error()
+		code = exitFail
+	}
+	for i, ID := range IDs {
+		if i < len(res) {
+			report.Results[ID] = jsonValue(res[i])
+		}
+	}
+	mu.Lock()
+	for _, t := range timings {
+		report.Nodes = append(report.Nodes, t)
+	}
+	mu.Unlock()
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		return report.Nodes[i].Start.Before(report.Nodes[j].Start)
+	})
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, "flow run:", err)
+		return exitFail
+	}
+	return code
+}
+
+// parseInput same as the builder default values, JSON or a string
+func parseInput(raw string) flow.Data {
+	var v flow.Data
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	return v
+}
+
+// jsonValue v or its string form if it can't be encoded
+func jsonValue(v interface{}) interface{} {
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+func registryNames() string {
+	names := []string{}
+	for k := range registries {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// since milliseconds from start to t, 0 if the node never started
+func since(start, t time.Time) float64 {
+	if start.IsZero() {
+		return 0
+	}
+	return ms(t.Sub(start))
+}
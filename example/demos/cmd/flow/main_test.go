@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow/internal/assert"
+)
+
+const testDoc = `{
+  "nodes": [
+    {"id": "x", "src": "Input", "prop": {"input name": "x"}},
+    {"id": "p", "src": "Input", "prop": {"input": "0"}},
+    {"id": "max", "src": "Max"}
+  ],
+  "links": [
+    {"from": "x", "to": "max", "in": 0},
+    {"from": "p", "to": "max", "in": 1}
+  ],
+  "triggers": []
+}`
+
+func runDoc(t *testing.T, stdin string, args ...string) (int, *Report) {
+	doc := filepath.Join(t.TempDir(), "doc.json")
+	if err := ioutil.WriteFile(doc, []byte(testDoc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	code := run(append([]string{"-doc", doc}, args...), strings.NewReader(stdin), out)
+	if out.Len() == 0 {
+		return code, nil
+	}
+	report := &Report{}
+	if err := json.Unmarshal(out.Bytes(), report); err != nil {
+		t.Fatal(err)
+	}
+	return code, report
+}
+
+func TestRun(t *testing.T) {
+	a := assert.A(t)
+
+	code, report := runDoc(t, "", "-in", "x=1.5", "-in", "0=4")
+	a.Eq(code, exitOK, "should succeed")
+	a.Eq(report.Results, map[string]interface{}{"max": 4.0}, "should use named and positional inputs")
+	a.Eq(report.Error, "", "should not report an error")
+
+	nodes := map[string]*NodeTiming{}
+	for _, n := range report.Nodes {
+		nodes[n.ID] = n
+	}
+	a.Eq(nodes["max"].Src, "Max", "should report the node src")
+	a.Eq(nodes["max"].Status, "finish", "should report the node status")
+	a.Eq(nodes["max"].Start.IsZero(), false, "should report the node start")
+
+	code, report = runDoc(t, "", "-optimize", "-in", "x=1.5", "-in", "0=4")
+	a.Eq(code, exitOK, "should succeed optimized")
+	a.Eq(report.Results, map[string]interface{}{"max": 4.0}, "optimized run should have the same results")
+}
+
+func TestRunStdin(t *testing.T) {
+	a := assert.A(t)
+
+	code, report := runDoc(t, `{"x": 5, "0": 2}`, "-stdin")
+	a.Eq(code, exitOK, "should succeed")
+	a.Eq(report.Results, map[string]interface{}{"max": 5.0}, "should read inputs from stdin")
+
+	code, _ = runDoc(t, `{"x":`, "-stdin")
+	a.Eq(code, exitUsage, "should fail on invalid stdin")
+}
+
+func TestRunFail(t *testing.T) {
+	a := assert.A(t)
+
+	code, report := runDoc(t, "", "-in", "0=4")
+	a.Eq(code, exitFail, "should fail without the named input")
+	a.NotEq(report.Error, "", "should report the error")
+	a.Eq(len(report.Results), 0, "should not report results")
+	a.Eq(since(time.Time{}, time.Now()), 0.0, "nodes failing before start should have no duration")
+}
+
+func TestRunUsage(t *testing.T) {
+	a := assert.A(t)
+
+	code := run(nil, strings.NewReader(""), &bytes.Buffer{})
+	a.Eq(code, exitUsage, "should require a document")
+
+	code, _ = runDoc(t, "", "unknown")
+	a.Eq(code, exitUsage, "should fail on unknown nodes")
+
+	code, _ = runDoc(t, "", "-registry", "nope")
+	a.Eq(code, exitUsage, "should fail on unknown registries")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gohxs/prettylog"
+	"github.com/hexasoftware/flow/example/demos/ops/decodeops"
+	"github.com/hexasoftware/flow/example/demos/ops/ml"
+	"github.com/hexasoftware/flow/worker"
+)
+
+var (
+	listen = flag.String("listen", ":2016", "address to listen, unix:///path for a socket")
+	pool   = flag.String("pool", "", "pool registration url to announce this worker")
+	addr   = flag.String("addr", "", "address announced to the pool, defaults to http://<hostname>:<port>")
+)
+
+// Serves the heavy ml and decode entries for a flowserver pool
+func main() {
+	flag.Parse()
+	prettylog.Global()
+
+	r := ml.New()
+	r.Merge(decodeops.New())
+
+	network, laddr := "tcp", *listen
+	if strings.HasPrefix(laddr, "unix://") {
+		network, laddr = "unix", strings.TrimPrefix(laddr, "unix://")
+		os.Remove(laddr)
+	}
+	l, err := net.Listen(network, laddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *pool != "" {
+		announce, err := announceAddr(*addr, *listen, l.Addr())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := worker.Announce(*pool, announce); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Println("Worker listening at:", *listen)
+	log.Fatal(http.Serve(l, worker.NewServer(r)))
+}
+
+// announceAddr address the pool uses to reach this worker, sockets are
+// announced as is and tcp listeners as an url with the hostname
+func announceAddr(addr, listen string, la net.Addr) (string, error) {
+	if addr != "" {
+		return addr, nil
+	}
+	if strings.HasPrefix(listen, "unix://") {
+		return listen, nil
+	}
+	host, port, err := net.SplitHostPort(la.String())
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		if host, err = os.Hostname(); err != nil {
+			return "", err
+		}
+	}
+	return "http://" + net.JoinHostPort(host, port), nil
+}
@@ -13,7 +13,7 @@ import (
 
 //New decoding ops
 func New() *registry.R {
-	r := registry.New()
+	r := registry.New().UseNamespace("decode")
 	r.Add(DecodeImage).Tags("experiment-decode")
 	return r
 }
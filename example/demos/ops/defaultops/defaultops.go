@@ -1,20 +1,23 @@
 package defaultops
 
+//go:generate go run github.com/hexasoftware/flow/registry/cmd/describegen
+
 import (
 	"math"
 	"math/rand"
+	"sync"
 
 	"github.com/hexasoftware/flow/registry"
 )
 
 // New create a registry
 func New() *registry.R {
-	r := registry.New()
+	r := registry.New().UseNamespace("default")
 	// String functions
 	// Math functions
 	r.Add(
 		math.Abs, math.Cos, math.Sin, math.Exp, math.Exp2, math.Tanh, math.Max, math.Min,
-	).Tags("math").Extra("style", registry.M{"color": "#386"})
+	).Tags("math").Extra("style", registry.M{"color": "#386"}).Pure()
 
 	registry.Describer(
 		r.Add(rand.Int, rand.Intn, rand.Float64),
@@ -24,7 +27,21 @@ func New() *registry.R {
 			}
 			return rand.Perm(n)
 		}),
+		// Seeded returns a source of pseudo-random floats in [0.0,1.0)
+		// repeating the same sequence for the same seed.
+		r.Add("Seeded", func(seed int64) func() float64 {
+			var mu sync.Mutex
+			rnd := rand.New(rand.NewSource(seed))
+			return func() float64 {
+				mu.Lock()
+				defer mu.Unlock()
+				return rnd.Float64()
+			}
+		}).Param(0, "", registry.Default(1)),
 	).Tags("rand").Extra("style", registry.M{"color": "#486"})
 
+	if err := describe(r); err != nil {
+		panic(err)
+	}
 	return r
 }
@@ -0,0 +1,86 @@
+// Code generated by describegen. DO NOT EDIT.
+
+package defaultops
+
+import "github.com/hexasoftware/flow/registry"
+
+// describe documents the entries of r from their source, an error means
+// the entries changed since the file was generated
+func describe(r *registry.R) error {
+	if err := r.Document("Abs", registry.Doc{
+		Description: "Abs returns the absolute value of x.",
+		Inputs:      []string{"x"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Cos", registry.Doc{
+		Description: "Cos returns the cosine of the radian argument x.",
+		Inputs:      []string{"x"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Exp", registry.Doc{
+		Description: "Exp returns e**x, the base-e exponential of x.",
+		Inputs:      []string{"x"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Exp2", registry.Doc{
+		Description: "Exp2 returns 2**x, the base-2 exponential of x.",
+		Inputs:      []string{"x"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Float64", registry.Doc{
+		Description: "Float64 returns, as a float64, a pseudo-random number in the half-open interval [0.0,1.0) from the default [Source].",
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Int", registry.Doc{
+		Description: "Int returns a non-negative pseudo-random int from the default [Source].",
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Intn", registry.Doc{
+		Description: "Intn returns, as an int, a non-negative pseudo-random number in the half-open interval [0,n) from the default [Source]. It panics if n <= 0.",
+		Inputs:      []string{"n"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Max", registry.Doc{
+		Description: "Max returns the larger of x or y.",
+		Inputs:      []string{"x", "y"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Min", registry.Doc{
+		Description: "Min returns the smaller of x or y.",
+		Inputs:      []string{"x", "y"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Perm", registry.Doc{
+		Inputs: []string{"n"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Seeded", registry.Doc{
+		Description: "Seeded returns a source of pseudo-random floats in [0.0,1.0) repeating the same sequence for the same seed.",
+		Params:      []string{"seed"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Sin", registry.Doc{
+		Description: "Sin returns the sine of the radian argument x.",
+		Inputs:      []string{"x"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Tanh", registry.Doc{
+		Description: "Tanh returns the hyperbolic tangent of x.",
+		Inputs:      []string{"x"},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
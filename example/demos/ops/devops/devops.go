@@ -13,7 +13,7 @@ import (
 // New create a new devops Registry
 func New() *registry.R {
 
-	r := registry.New()
+	r := registry.New().UseNamespace("dev")
 
 	r.Add(
 		dockerNew,
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
-	"strings"
 	"time"
 
 	"github.com/hexasoftware/flow"
@@ -14,7 +13,7 @@ import (
 // New create new registry with generic operations
 func New() *registry.R {
 
-	r := registry.New()
+	r := registry.New().UseNamespace("generic")
 	// Test functions
 	r.Add(testErrorPanic, testErrorDelayed, testRandomError).
 		Tags("testing")
@@ -24,8 +23,6 @@ func New() *registry.R {
 		r.Add("waitRandom", waitRandom),
 	).Tags("testing").Extra("style", map[string]string{"color": "#8a5"})
 
-	r.Add(strings.Split, strings.Join).Tags("strings")
-
 	return r
 }
 func wait(data flow.Data, n int) flow.Data {
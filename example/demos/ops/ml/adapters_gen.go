@@ -0,0 +1,249 @@
+// Code generated by adaptergen. DO NOT EDIT.
+
+package ml
+
+import (
+	"image"
+	"reflect"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/flowserver"
+	"github.com/hexasoftware/flow/registry"
+	"gonum.org/v1/gonum/mat"
+)
+
+func init() {
+	registry.RegisterAdapter(reflect.TypeOf((func(Matrix) Matrix)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(Matrix) Matrix)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(Matrix)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(Matrix) []float64)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(Matrix) []float64)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(Matrix)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(Matrix, Matrix) Matrix)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(Matrix, Matrix) Matrix)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 2 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(Matrix)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(Matrix)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0, p1), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func([]byte, int, int) (flowserver.Base64Data, error))(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func([]byte, int, int) (flowserver.Base64Data, error))
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 3 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].([]byte)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(int)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			p2, ok := params[2].(int)
+			if !ok && params[2] != nil {
+				return nil, registry.ErrInput
+			}
+			r, err := f(p0, p1, p2)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(float64, Matrix) Matrix)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(float64, Matrix) Matrix)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 2 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(float64)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(Matrix)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0, p1), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(flow.Data, flow.Data, flow.Data, flow.Data) []flow.Data)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(flow.Data, flow.Data, flow.Data, flow.Data) []flow.Data)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 4 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(flow.Data)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(flow.Data)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			p2, ok := params[2].(flow.Data)
+			if !ok && params[2] != nil {
+				return nil, registry.ErrInput
+			}
+			p3, ok := params[3].(flow.Data)
+			if !ok && params[3] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0, p1, p2, p3), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(image.Image) (flowserver.Base64Data, error))(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(image.Image) (flowserver.Base64Data, error))
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(image.Image)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			r, err := f(p0)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(image.Image) (mat.Matrix, error))(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(image.Image) (mat.Matrix, error))
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(image.Image)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			r, err := f(p0)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(int) []float64)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(int) []float64)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(int)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(int, int) Matrix)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(int, int) Matrix)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 2 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(int)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(int)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0, p1), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(int, int, []float64) Matrix)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(int, int, []float64) Matrix)
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 3 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(int)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(int)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			p2, ok := params[2].([]float64)
+			if !ok && params[2] != nil {
+				return nil, registry.ErrInput
+			}
+			return f(p0, p1, p2), nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(mat.Matrix) (flowserver.Base64Data, error))(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(mat.Matrix) (flowserver.Base64Data, error))
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(mat.Matrix)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			r, err := f(p0)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	})
+	registry.RegisterAdapter(reflect.TypeOf((func(mat.Matrix, mat.Matrix) (mat.Matrix, error))(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(mat.Matrix, mat.Matrix) (mat.Matrix, error))
+		return func(params ...interface{}) (interface{}, error) {
+			if len(params) != 2 {
+				return nil, registry.ErrInput
+			}
+			p0, ok := params[0].(mat.Matrix)
+			if !ok && params[0] != nil {
+				return nil, registry.ErrInput
+			}
+			p1, ok := params[1].(mat.Matrix)
+			if !ok && params[1] != nil {
+				return nil, registry.ErrInput
+			}
+			r, err := f(p0, p1)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	})
+}
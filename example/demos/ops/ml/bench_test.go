@@ -0,0 +1,83 @@
+package ml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/registry"
+	"gonum.org/v1/gonum/mat"
+)
+
+var benchOps = []struct {
+	name   string
+	params []interface{}
+}{
+	{"matAdd", []interface{}{benchMat(), benchMat()}},
+	{"matMul", []interface{}{benchMat(), benchMat()}},
+	{"matScale", []interface{}{2.0, benchMat()}},
+	{"matTranspose", []interface{}{benchMat()}},
+	{"toFloatArr", []interface{}{benchMat()}},
+}
+
+func benchMat() Matrix {
+	return mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+}
+
+// BenchmarkReflect calls the entries the way the executor did before
+// adapters, through reflect.Value.Call
+func BenchmarkReflect(b *testing.B) {
+	r := New()
+	for _, bo := range benchOps {
+		fn, err := r.Get(bo.name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		fnval := reflect.ValueOf(fn)
+		b.Run(bo.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				callParam := make([]reflect.Value, len(bo.params))
+				for j, p := range bo.params {
+					callParam[j] = reflect.ValueOf(p)
+				}
+				fnval.Call(callParam)[0].Interface()
+			}
+		})
+	}
+}
+
+// BenchmarkAdapter calls the entries through the generated adapters
+func BenchmarkAdapter(b *testing.B) {
+	r := New()
+	for _, bo := range benchOps {
+		fn, err := r.Get(bo.name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		call := registry.CallerOf(fn)
+		b.Run(bo.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := call(bo.params...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFlow runs a small graph of the ops through a flow session
+func BenchmarkFlow(b *testing.B) {
+	f := flow.New().UseRegistry(New())
+	op := f.Op("toFloatArr",
+		f.Op("matAdd",
+			f.Op("matMul", f.In(0), f.Op("matTranspose", f.In(0))),
+			f.Op("matScale", 2.0, f.In(0)),
+		),
+	)
+	m := benchMat()
+	for i := 0; i < b.N; i++ {
+		if _, err := op.Process(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
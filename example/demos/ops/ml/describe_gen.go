@@ -0,0 +1,106 @@
+// Code generated by describegen. DO NOT EDIT.
+
+package ml
+
+import "github.com/hexasoftware/flow/registry"
+
+// describe documents the entries of r from their source, an error means
+// the entries changed since the file was generated
+func describe(r *registry.R) error {
+	if err := r.Document("displayGrayMat", registry.Doc{
+		Inputs: []string{"m"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("displayImg", registry.Doc{
+		Inputs: []string{"img"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("imageToGrayMatrix", registry.Doc{
+		Description: "imageToMat create a grayscaled matrix of the image",
+		Inputs:      []string{"im"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matAdd", registry.Doc{
+		Inputs: []string{"a", "b"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matConv", registry.Doc{
+		Description: "Convolution matrix",
+		Inputs:      []string{"a", "conv"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matMul", registry.Doc{
+		Inputs: []string{"a", "b"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matMulElem", registry.Doc{
+		Inputs: []string{"a", "b"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matNew", registry.Doc{
+		Inputs: []string{"r", "c", "data"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matNewRand", registry.Doc{
+		Inputs: []string{"r", "c"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matScale", registry.Doc{
+		Description: "Scalar per element multiplication",
+		Inputs:      []string{"f", "a"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matSigmoid", registry.Doc{
+		Description: "sigmoid Activator",
+		Inputs:      []string{"a"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matSigmoidPrime", registry.Doc{
+		Inputs: []string{"a"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matSub", registry.Doc{
+		Inputs: []string{"a", "b"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("matTranspose", registry.Doc{
+		Inputs: []string{"a"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("normFloat", registry.Doc{
+		Inputs: []string{"n"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("toFloatArr", registry.Doc{
+		Inputs: []string{"a"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("toGrayImage", registry.Doc{
+		Description: "Test",
+		Inputs:      []string{"data", "w", "h"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("train", registry.Doc{
+		Inputs: []string{"a", "b", "c", "d"},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,27 +1,35 @@
 // Package ml machine learning operations for flow
 package ml
 
+//go:generate go run github.com/hexasoftware/flow/registry/cmd/adaptergen
+//go:generate go run github.com/hexasoftware/flow/registry/cmd/describegen
+
 import (
 	"math/rand"
 
 	"github.com/hexasoftware/flow"
 	"github.com/hexasoftware/flow/registry"
+	"github.com/hexasoftware/flow/worker"
 	"gonum.org/v1/gonum/mat"
 )
 
+func init() {
+	// matrices reach remote workers behind mat.Matrix
+	worker.Register(&mat.Dense{}, &mat.VecDense{})
+}
+
 // Matrix wrapper
 type Matrix = mat.Matrix
 
 // New registry
 func New() *registry.R {
 
-	r := registry.New()
+	r := registry.New().UseNamespace("ml")
 
 	registry.Describer(
-		r.Add(matNew).Inputs("rows", "columns", "data"),
+		r.Add(matNew).Inputs("rows", "columns", "data").Pure(),
+		r.Add(normFloat, matNewRand),
 		r.Add(
-			normFloat,
-			matNewRand,
 			matAdd,
 			matSub,
 			matMul,
@@ -31,7 +39,7 @@ func New() *registry.R {
 			matSigmoid,
 			matSigmoidPrime,
 			toFloatArr,
-		),
+		).Pure(),
 		r.Add("train", func(a, b, c, d flow.Data) []flow.Data {
 			return []flow.Data{a, b, c, d}
 		}).Inputs("dummy", "dummy", "dummy", "dummy"),
@@ -42,11 +50,16 @@ func New() *registry.R {
 	registry.Describer(
 		r.Add(imageToGrayMatrix),
 		r.Add(displayImg),
-		r.Add(toGrayImage),
+		r.Add(toGrayImage).
+			Input(1, "width", registry.Default(28), registry.Range(1, 4096)).
+			Input(2, "height", registry.Default(28), registry.Range(1, 4096)),
 		r.Add(matConv).Inputs("matrix", "conv"),
 		r.Add(displayGrayMat),
 	).Tags("experiment")
 
+	if err := describe(r); err != nil {
+		panic(err)
+	}
 	return r
 }
 
@@ -0,0 +1,45 @@
+// Code generated by describegen. DO NOT EDIT.
+
+package stringops
+
+import "github.com/hexasoftware/flow/registry"
+
+// describe documents the entries of r from their source, an error means
+// the entries changed since the file was generated
+func describe(r *registry.R) error {
+	if err := r.Document("Cat", registry.Doc{
+		Inputs: []string{"a", "b"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Compare", registry.Doc{
+		Description: "Compare returns an integer comparing two strings lexicographically. The result will be 0 if a == b, -1 if a < b, and +1 if a > b.",
+		Inputs:      []string{"a", "b"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Contains", registry.Doc{
+		Description: "Contains reports whether substr is within s.",
+		Inputs:      []string{"s", "substr"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Join", registry.Doc{
+		Description: "Join concatenates the elements of its first argument to create a single string. The separator string sep is placed between elements in the resulting string.",
+		Inputs:      []string{"elems", "sep"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("Split", registry.Doc{
+		Description: "Split slices s into all substrings separated by sep and returns a slice of the substrings between those separators.",
+		Inputs:      []string{"s", "sep"},
+	}); err != nil {
+		return err
+	}
+	if err := r.Document("ToString", registry.Doc{
+		Inputs: []string{"a"},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package stringops
 
+//go:generate go run github.com/hexasoftware/flow/registry/cmd/describegen
+
 import (
 	"fmt"
 	"strings"
@@ -9,14 +11,17 @@ import (
 
 // New create string operations
 func New() *registry.R {
-	r := registry.New()
+	r := registry.New().UseNamespace("strings")
 	registry.Describer(
 		r.Add(strings.Split).Inputs("string", "separator"),
 		r.Add(strings.Join).Inputs("", "sep"),
 		r.Add(strings.Compare, strings.Contains),
 		r.Add("Cat", func(a, b string) string { return a + " " + b }),
 		r.Add("ToString", func(a interface{}) string { return fmt.Sprint(a) }),
-	).Tags("string").Extra("style", registry.M{"color": "#839"})
+	).Tags("string").Extra("style", registry.M{"color": "#839"}).Pure()
 
+	if err := describe(r); err != nil {
+		panic(err)
+	}
 	return r
 }
@@ -9,7 +9,7 @@ import (
 
 // New creates web operations for flow
 func New() *registry.R {
-	r := registry.New()
+	r := registry.New().UseNamespace("web")
 
 	r.Add(httpGet).Tags("http").
 		Extra("style", registry.M{"color": "#828"})
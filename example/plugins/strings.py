@@ -0,0 +1,29 @@
+#!/usr/bin/env python3
+# Sample flow plugin, load with registry.R.LoadPlugins("example/plugins", timeout)
+import json
+import sys
+
+ENTRIES = [
+    {"name": "upper", "description": "upper case a string", "tags": ["plugin"],
+     "inputs": [{"type": "string", "name": "str"}], "output": {"type": "string", "name": "upper"}},
+    {"name": "repeat", "description": "repeat a string n times", "tags": ["plugin"],
+     "inputs": [{"type": "string", "name": "str"}, {"type": "int", "name": "n"}],
+     "output": {"type": "string", "name": "repeated"}},
+]
+
+CALLS = {
+    "upper": lambda s: s.upper(),
+    "repeat": lambda s, n: s * int(n),
+}
+
+for line in sys.stdin:
+    req = json.loads(line)
+    res = {"id": req["id"]}
+    try:
+        if req["method"] == "describe":
+            res["result"] = ENTRIES
+        elif req["method"] == "call":
+            res["result"] = CALLS[req["entry"]](*req.get("args", []))
+    except Exception as e:
+        res["error"] = str(e)
+    print(json.dumps(res), flush=True)
@@ -0,0 +1,191 @@
+package flow
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+// Plan execution plan of a set of operations, built without running them
+type Plan struct {
+	Steps  []*PlanStep // topological order, inputs before operations
+	Levels [][]int     // step IDs by level, steps in the same level can run in parallel
+	Shared []int       // steps requested by more than one operation, executed once per session
+
+	Hazards []Hazard
+
+	CriticalPath []int         // step IDs from first to last
+	Estimate     time.Duration // estimated duration of the critical path
+}
+
+// PlanStep an operation in the plan
+type PlanStep struct {
+	ID       int
+	Kind     string
+	Name     string
+	Source   string // file:line
+	Inputs   []int
+	Level    int
+	Users    int           // number of operations using this step result
+	Estimate time.Duration // average execution time from previous runs once inputs are resolved, 0 if unknown
+}
+
+// Hazard var read and write with no defined ordering between them
+type Hazard struct {
+	Var   string
+	Read  int // step reading the var
+	Write int // step writing the var
+}
+
+// Explain returns the execution plan for ops, or for every operation of
+// the flow if none given
+func (f *Flow) Explain(ops ...Operation) *Plan {
+	roots := make([]*operation, 0, len(ops))
+	for _, op := range ops {
+		roots = append(roots, op.(*operation))
+	}
+	if len(roots) == 0 {
+		roots = f.operations
+	}
+
+	plan := &Plan{}
+	steps := map[*operation]*PlanStep{}
+	// ancestors of every step, used for var ordering
+	ancestors := map[*operation]map[*operation]bool{}
+
+	walk(roots, func(op *operation) error {
+		_, file := path.Split(op.file)
+		st := &PlanStep{
+			ID:     op.id,
+			Kind:   op.kind,
+			Name:   op.name,
+			Source: fmt.Sprintf("%s:%d", file, op.line),
+		}
+		steps[op] = st
+		anc := map[*operation]bool{}
+		used := map[*operation]bool{}
+		for _, in := range op.inputs {
+			ist := steps[in]
+			if !used[in] { // count each user once
+				used[in] = true
+				ist.Users++
+			}
+			st.Inputs = append(st.Inputs, ist.ID)
+			if ist.Level+1 > st.Level {
+				st.Level = ist.Level + 1
+			}
+			anc[in] = true
+			for a := range ancestors[in] {
+				anc[a] = true
+			}
+		}
+		ancestors[op] = anc
+		if op.kind == "func" {
+			count, sum := opExecDuration.Stats(op.metricLabels()...)
+			if count > 0 {
+				st.Estimate = time.Duration(sum / float64(count) * float64(time.Second))
+			}
+		}
+		plan.Steps = append(plan.Steps, st)
+		return nil
+	})
+
+	for _, st := range plan.Steps {
+		for len(plan.Levels) <= st.Level {
+			plan.Levels = append(plan.Levels, nil)
+		}
+		plan.Levels[st.Level] = append(plan.Levels[st.Level], st.ID)
+		if st.Users > 1 {
+			plan.Shared = append(plan.Shared, st.ID)
+		}
+	}
+
+	// Var hazards, reads and writes of the same var where none depends on the other
+	for r := range steps {
+		if r.kind != "var" {
+			continue
+		}
+		for w := range steps {
+			if w.kind != "setvar" || w.name != r.name || ancestors[r][w] || ancestors[w][r] {
+				continue
+			}
+			plan.Hazards = append(plan.Hazards, Hazard{Var: r.name, Read: r.id, Write: w.id})
+		}
+	}
+	sort.Slice(plan.Hazards, func(i, j int) bool {
+		if plan.Hazards[i].Read != plan.Hazards[j].Read {
+			return plan.Hazards[i].Read < plan.Hazards[j].Read
+		}
+		return plan.Hazards[i].Write < plan.Hazards[j].Write
+	})
+
+	// Critical path, longest estimated chain, deepest chain if unknown
+	type cost struct {
+		dur   time.Duration
+		level int
+		prev  int
+	}
+	costs := map[int]cost{}
+	var last *PlanStep
+	for _, st := range plan.Steps { // already in topological order
+		c := cost{dur: st.Estimate, level: st.Level, prev: -1}
+		best := cost{prev: -1}
+		for _, in := range st.Inputs {
+			ic := costs[in]
+			if ic.dur > best.dur || (ic.dur == best.dur && ic.level >= best.level) {
+				best = ic
+				best.prev = in
+			}
+		}
+		c.dur += best.dur
+		c.prev = best.prev
+		costs[st.ID] = c
+		if last == nil || c.dur > costs[last.ID].dur ||
+			(c.dur == costs[last.ID].dur && c.level > costs[last.ID].level) {
+			last = st
+		}
+	}
+	if last != nil {
+		plan.Estimate = costs[last.ID].dur
+		for id := last.ID; id != -1; id = costs[id].prev {
+			plan.CriticalPath = append([]int{id}, plan.CriticalPath...)
+		}
+	}
+
+	return plan
+}
+
+func (p *Plan) String() string {
+	ret := bytes.NewBuffer(nil)
+	fmt.Fprintf(ret, "Plan\n")
+	for l, ids := range p.Levels {
+		fmt.Fprintf(ret, "level %d:\n", l)
+		for _, id := range ids {
+			st := p.step(id)
+			fmt.Fprintf(ret, "  [%v] %s(%s) %v <- %v", st.ID, st.Kind, st.Name, st.Source, st.Inputs)
+			if st.Estimate > 0 {
+				fmt.Fprintf(ret, " ~%v", st.Estimate)
+			}
+			fmt.Fprintf(ret, "\n")
+		}
+	}
+	if len(p.Shared) > 0 {
+		fmt.Fprintf(ret, "shared: %v\n", p.Shared)
+	}
+	for _, h := range p.Hazards {
+		fmt.Fprintf(ret, "hazard: var %q read [%v] and write [%v] are not ordered\n", h.Var, h.Read, h.Write)
+	}
+	fmt.Fprintf(ret, "critical path: %v ~%v\n", p.CriticalPath, p.Estimate)
+	return ret.String()
+}
+
+func (p *Plan) step(id int) *PlanStep {
+	for _, st := range p.Steps {
+		if st.ID == id {
+			return st
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package flow_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestExplain(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	in := f.In(0)
+	mul := f.Op("vecmul", in, []float32{2, 2})
+	add := f.Op("vecadd", mul, mul)
+	div := f.Op("vecdiv", add, in)
+
+	plan := f.Explain(div)
+	t.Log(plan)
+
+	a.Eq(len(plan.Levels), 4, "should have 4 levels")
+	a.Eq(len(plan.Levels[0]), 2, "input and const should be in the first level")
+	a.Eq(plan.Shared, []int{1}, "input should be shared")
+	a.Eq(plan.Steps[2].Users, 1, "mul should have one user")
+	a.Eq(len(plan.CriticalPath), 4, "critical path should cross every level")
+	a.Eq(plan.CriticalPath[3], plan.Steps[len(plan.Steps)-1].ID, "critical path should end in div")
+}
+
+func TestExplainHazard(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	read := f.Var("v", 1)
+	write := f.SetVar("v", 2)
+	res := f.Op("add", read, write)
+
+	plan := f.Explain(res)
+	a.Eq(len(plan.Hazards), 1, "unordered read and write should be a hazard")
+	a.Eq(plan.Hazards[0].Var, "v", "hazard should be on var v")
+
+	f = flow.New()
+	write = f.SetVar("v", 2)
+	res = f.Op("add", f.Var("v", write), 1)
+	plan = f.Explain(res)
+	a.Eq(len(plan.Hazards), 0, "read depending on the write should not be a hazard")
+}
+
+func TestExplainDoesNotRun(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+	inc := f.Op("inc")
+	f.Explain()
+	res, err := inc.Process()
+	a.Eq(err, nil, "should not error")
+	a.Eq(res, 1, "explain should not execute operations")
+}
+
+func TestExplainEstimate(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("explainSlow", func(v int) int {
+		time.Sleep(30 * time.Millisecond)
+		return v
+	})
+	f := flow.New().UseRegistry(r)
+	last := f.Op("explainSlow", f.Op("explainSlow", f.Op("explainSlow", 1)))
+	_, err := last.Process()
+	a.Eq(err, nil, "should run")
+
+	plan := f.Explain(last)
+	for _, st := range plan.Steps {
+		if st.Kind != "func" {
+			continue
+		}
+		a.Eq(st.Estimate < 60*time.Millisecond, true, "estimate should not include waiting for inputs")
+	}
+	a.Eq(plan.Estimate < 150*time.Millisecond, true, "critical path should count each step once")
+	a.Eq(plan.Estimate >= 60*time.Millisecond, true, "critical path should sum the steps")
+}
@@ -17,14 +17,27 @@ type Data = interface{}
 // We could Create a single array of operations
 // refs would only mean id, types would be embed in operation
 type Flow struct {
-	//sync.Mutex // Needed?
+	// edits and Optimize take the write lock, running sessions the read
+	// lock, edits wait for running sessions to finish
+	mu         sync.RWMutex
 	registry   *registry.R
 	Data       sync.Map // Should be named, to fetch later
 	consts     []Data
 	operations []*operation
+	pruned     []*operation // removed by Optimize, still usable
+	lastID     int
+	inputs     []*operation // named inputs
+	outputs    []namedOutput
 
 	// Experimental run Event
 	hooks Hooks
+
+	exporter   SpanExporter
+	recorder   *Recorder
+	replay     *replay
+	identities sync.Map // cachedIdentity by operation, for records
+
+	batchWorkers int
 }
 
 // New create a new flow
@@ -44,7 +57,13 @@ func (f *Flow) UseRegistry(r *registry.R) *Flow {
 	return f
 }
 
-// Analyse every operations
+// Registry returns the registry in use
+func (f *Flow) Registry() *registry.R {
+	return f.registry
+}
+
+// Analyse every operations, it executes every operation in a new session
+// use Explain to inspect a flow without running it
 func (f *Flow) Analyse(w io.Writer, params ...Data) {
 	if w == nil {
 		w = os.Stdout
@@ -52,6 +71,9 @@ func (f *Flow) Analyse(w io.Writer, params ...Data) {
 	fmt.Fprintf(w, "Ops analysis:\n")
 
 	for k, op := range f.operations {
+		if op.kind != "func" {
+			continue
+		}
 		fw := bytes.NewBuffer(nil)
 		//fmt.Fprintf(w, "  [%s] (%v)", k, op.name)
 		fmt.Fprintf(fw, "  [%v] %s(", k, op.name)
@@ -101,6 +123,9 @@ func (f *Flow) String() string {
 
 	fmt.Fprintf(ret, "operations:\n")
 	for k, op := range f.operations {
+		if op.kind != "func" {
+			continue
+		}
 		fmt.Fprintf(ret, "  [%v] %s(", k, op.name)
 		for j, in := range op.inputs {
 			if j != 0 {
@@ -177,6 +177,28 @@ func TestLocalRegistry(t *testing.T) {
 	a.NotEq(err, nil, "flow should contain an error")
 }
 
+func TestFactory(t *testing.T) {
+	a := assert.A(t)
+
+	r := registry.New()
+	r.Add("scale", func(factor float64) func(float64) float64 {
+		return func(v float64) float64 { return v * factor }
+	}).Param(0, "factor", registry.Default(2), registry.Range(0, 10))
+
+	f := flow.New()
+	f.UseRegistry(r)
+	res, err := f.Factory("scale", []flow.Data{3}, 2.0).Process()
+	a.Eq(err, nil, "factory should not fail")
+	a.Eq(res, 6.0, "should use the config param")
+
+	res, err = f.Op("scale", 2.0).Process()
+	a.Eq(err, nil, "factory op should not fail")
+	a.Eq(res, 4.0, "should use the default param")
+
+	_, err = f.Factory("scale", []flow.Data{11}, 2.0).Process()
+	a.NotEq(err, nil, "should reject params out of range")
+}
+
 func init() {
 	registry.Add("vecmul", VecMul)
 	registry.Add("vecadd", VecAdd)
@@ -255,3 +277,32 @@ func Min(p ...int) int {
 	}
 	return min
 }
+
+func TestInputConstraints(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("train", func(rate float64, act string) string { return act }).
+		Input(0, "rate", registry.Range(0, 1)).
+		Input(1, "act", registry.Enum("sigmoid", "relu"))
+	f := flow.New().UseRegistry(r)
+
+	res, err := f.Op("train", 0.5, "relu").Process()
+	a.Eq(err, nil, "should accept valid values")
+	a.Eq(res, "relu", "should run")
+
+	_, err = f.Op("train", 2.0, "relu").Process()
+	a.NotEq(err, nil, "should reject const values out of range")
+
+	op := f.Op("train", f.Input("rate", nil, nil), f.In(0))
+	sess := f.NewSession()
+	sess.Inputs("tanh")
+	sess.NamedInputs(map[string]flow.Data{"rate": 0.1})
+	_, err = sess.Run(op)
+	a.NotEq(err, nil, "should reject inputs out of enum")
+
+	sess = f.NewSession()
+	sess.Inputs("sigmoid")
+	sess.NamedInputs(map[string]flow.Data{"rate": 1.5})
+	_, err = sess.Run(op)
+	a.NotEq(err, nil, "should reject named inputs out of range")
+}
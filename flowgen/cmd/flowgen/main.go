@@ -0,0 +1,179 @@
+// Command flowgen compiles a flow document or a flow serialized with
+// json.Marshal into plain Go.
+//
+// Registry packages must provide a New func returning a *registry.R, they
+// are merged in order. flowgen builds and runs a small program importing
+// them, it must run within the module that can import the packages:
+//
+//	flowgen -doc flow.json -registry github.com/me/ops,github.com/me/more -o flow_gen.go
+//	flowgen -flow saved.json -registry github.com/me/ops -o flow_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	var (
+		docPath  = flag.String("doc", "", "flow document")
+		flowPath = flag.String("flow", "", "serialized flow, its named outputs are generated")
+		regs     = flag.String("registry", "", "comma separated registry package paths")
+		nodes    = flag.String("nodes", "", "comma separated document node IDs, defaults to nodes without outgoing links")
+		pkg      = flag.String("pkg", "main", "generated package name")
+		pkgPath  = flag.String("pkgpath", "", "generated package import path")
+		funcName = flag.String("func", "Run", "generated func name")
+		out      = flag.String("o", "", "output file, defaults to stdout")
+	)
+	flag.Parse()
+	if (*docPath == "") == (*flowPath == "") || *regs == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	path, serialized := *docPath, false
+	if *flowPath != "" {
+		path, serialized = *flowPath, true
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	params := driverParams{
+		Path:       abs,
+		Serialized: serialized,
+		Registries: split(*regs),
+		Nodes:      split(*nodes),
+		Package:    *pkg,
+		PkgPath:    *pkgPath,
+		Func:       *funcName,
+	}
+
+	src, err := generate(params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate runs the driver program within the current module
+func generate(params driverParams) ([]byte, error) {
+	dir, err := ioutil.TempDir(".", "flowgen")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		return nil, err
+	}
+	err = driverTmpl.Execute(f, params)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := bytes.NewBuffer(nil)
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("flowgen: %v", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func split(s string) []string {
+	ret := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+type driverParams struct {
+	Path       string
+	Serialized bool // Path is a serialized flow instead of a document
+	Registries []string
+	Nodes      []string
+	Package    string
+	PkgPath    string
+	Func       string
+}
+
+var driverTmpl = template.Must(template.New("driver").Parse(`package main
+
+import (
+{{- if not .Serialized}}
+	"encoding/json"
+{{- end}}
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/hexasoftware/flow/flowgen"
+{{- if not .Serialized}}
+	"github.com/hexasoftware/flow/flowserver/flowbuilder"
+{{- end}}
+	"github.com/hexasoftware/flow/registry"
+{{- range $i, $r := .Registries}}
+	r{{$i}} {{printf "%q" $r}}
+{{- end}}
+)
+
+func main() {
+	log.SetOutput(ioutil.Discard) // builder logs
+
+	r := registry.New()
+{{- range $i, $r := .Registries}}
+	r.Merge(r{{$i}}.New())
+{{- end}}
+
+	data, err := ioutil.ReadFile({{printf "%q" .Path}})
+	if err != nil {
+		fail(err)
+	}
+	opt := flowgen.Options{
+		Package: {{printf "%q" .Package}},
+		PkgPath: {{printf "%q" .PkgPath}},
+		Func:    {{printf "%q" .Func}},
+	}
+{{- if .Serialized}}
+	if err := flowgen.GenerateJSON(os.Stdout, data, r, opt); err != nil {
+		fail(err)
+	}
+{{- else}}
+	doc := &flowbuilder.FlowDocument{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		fail(err)
+	}
+	nodes := []string{ {{- range .Nodes}}{{printf "%q" .}}, {{end -}} }
+	if err := flowgen.GenerateDocument(os.Stdout, doc, r, opt, nodes...); err != nil {
+		fail(err)
+	}
+{{- end}}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+`))
@@ -0,0 +1,53 @@
+package flowgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/flowserver/flowbuilder"
+	"github.com/hexasoftware/flow/registry"
+)
+
+// GenerateJSON loads a flow serialized with json.Marshal using the
+// registry r and generates the func computing its named outputs
+func GenerateJSON(w io.Writer, data []byte, r *registry.R, opt Options) error {
+	f := flow.New().UseRegistry(r)
+	if err := json.Unmarshal(data, f); err != nil {
+		return err
+	}
+	return Generate(w, f, r, opt)
+}
+
+// GenerateDocument builds doc with the registry r and generates the func
+// computing the nodes IDs, every node without outgoing links is used if no
+// IDs are given, triggers are ignored
+func GenerateDocument(w io.Writer, doc *flowbuilder.FlowDocument, r *registry.R, opt Options, IDs ...string) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	fb := flowbuilder.New(r).Load(data)
+	if fb.Err != nil {
+		return fb.Err
+	}
+
+	if len(IDs) == 0 {
+		IDs = doc.Sinks()
+	}
+	ops := make([]flow.Operation, len(IDs))
+	for i, ID := range IDs {
+		if doc.FetchNodeByID(ID) == nil {
+			return fmt.Errorf("node not found [%v]", ID)
+		}
+		ops[i] = fb.Build(ID)
+		if fb.Err != nil {
+			return fb.Err
+		}
+	}
+	if len(ops) == 0 {
+		return ErrNoOutput
+	}
+	return Generate(w, fb.Flow(), r, opt, ops...)
+}
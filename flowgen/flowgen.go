@@ -0,0 +1,754 @@
+// Package flowgen compiles a flow into plain Go, the generated func calls
+// the registry functions directly in dependency order and runs independent
+// operations in goroutines, type errors in the graph become compile errors.
+//
+// Flows are given as a *flow.Flow built in Go (Generate), as a Flow
+// serialized with json.Marshal (GenerateJSON) or as a flowbuilder document
+// (GenerateDocument)
+package flowgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/codegen"
+	"github.com/hexasoftware/flow/registry"
+)
+
+// Generator errors
+var (
+	ErrNoOutput    = errors.New("no operations to generate")
+	ErrUnsupported = errors.New("unsupported operation")
+)
+
+// Options for the generated code
+type Options struct {
+	Package string // package name, defaults to main
+	PkgPath string // import path of the generated package, its functions are called unqualified
+	Func    string // name of the generated func, defaults to Run
+}
+
+// Generate writes the Go source of a func computing ops, the flow named
+// outputs are used if no ops are given.
+//
+// Positional inputs and named inputs become parameters, input types are
+// taken from the declaration or from the first operation using them, named
+// input defaults are not applied. Functions that can't be referenced by name
+// (closures, methods, factories and unexported functions of other packages)
+// are resolved from a registry by a generated Bind func. Var and SetVar
+// operations are not supported
+func Generate(w io.Writer, f *flow.Flow, r *registry.R, opt Options, ops ...flow.Operation) error {
+	if opt.Package == "" {
+		opt.Package = "main"
+	}
+	if opt.Func == "" {
+		opt.Func = "Run"
+	}
+	g := &generator{
+		opt:     opt,
+		r:       r,
+		imports: map[string]string{},
+		idents:  map[string]bool{},
+		nodes:   map[flow.Operation]*node{},
+	}
+
+	outNames := []string{}
+	if len(ops) == 0 {
+		ops = f.Outputs()
+		for _, p := range f.Signature().Outputs {
+			outNames = append(outNames, p.Name)
+		}
+	}
+	if len(ops) == 0 {
+		return ErrNoOutput
+	}
+
+	if err := g.analyse(f, ops); err != nil {
+		return err
+	}
+	g.name(ops, outNames)
+	src, err := g.emit(ops)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// node generation state of an operation
+type node struct {
+	op    flow.Operation
+	typ   reflect.Type // result type, nil if none
+	level int          // 0 for values, 1 + deepest input for calls
+	ident string       // variable or parameter holding the result
+	index int          // parameter order of inputs
+
+	// func operations
+	callee string       // qualified func
+	bind   *bind        // bound func if not called directly
+	fnType reflect.Type // func type
+	lit    string       // const literal
+}
+
+type bind struct {
+	ident string
+	entry string
+	typ   reflect.Type
+}
+
+type generator struct {
+	opt     Options
+	r       *registry.R
+	imports map[string]string // import path to alias
+	idents  map[string]bool
+	order   []*node
+	nodes   map[flow.Operation]*node
+	binds   []*bind
+	levels  [][]*node // calls by level, calls in the same level run in goroutines
+	results []string  // result names
+}
+
+func (g *generator) analyse(f *flow.Flow, ops []flow.Operation) error {
+	sig := f.Signature()
+	err := flow.Walk(func(op flow.Operation) error {
+		n := &node{op: op}
+		g.nodes[op] = n
+		g.order = append(g.order, n)
+
+		switch op.Kind() {
+		case "func":
+			return g.analyseFunc(n)
+		case "alias":
+			in := g.nodes[op.Inputs()[0]]
+			n.typ, n.level = in.typ, in.level
+		case "const":
+			v, err := op.Process()
+			if err != nil {
+				return err
+			}
+			if v != nil {
+				n.typ = reflect.TypeOf(v)
+			}
+		case "in":
+			n.index, _ = strconv.Atoi(op.Name())
+		case "input":
+			for i, p := range sig.Inputs {
+				if p.Name == op.Name() {
+					n.typ, n.index = p.Type, i
+				}
+			}
+		case "error":
+			_, err := op.Process()
+			return fmt.Errorf("operation [%d]: %v", op.ID(), err)
+		default:
+			return fmt.Errorf("%w: operation [%d] %s", ErrUnsupported, op.ID(), op.Kind())
+		}
+		return nil
+	}, ops...)
+	if err != nil {
+		return err
+	}
+
+	// Input types from the operations using them
+	for _, n := range g.order {
+		if n.fnType == nil {
+			continue
+		}
+		for i, in := range n.op.Inputs() {
+			inNode := g.nodes[in]
+			if inNode.typ == nil && (in.Kind() == "in" || in.Kind() == "input") {
+				inNode.typ = paramType(n.fnType, i)
+			}
+		}
+	}
+
+	// Literals
+	for _, n := range g.order {
+		if n.op.Kind() != "const" {
+			continue
+		}
+		v, _ := n.op.Process()
+		if v == nil {
+			continue
+		}
+		lit, err := g.literal(reflect.ValueOf(v), true)
+		if err != nil {
+			return fmt.Errorf("operation [%d]: %v", n.op.ID(), err)
+		}
+		n.lit = lit
+	}
+
+	for _, n := range g.order {
+		if n.fnType == nil {
+			continue
+		}
+		for len(g.levels) < n.level {
+			g.levels = append(g.levels, nil)
+		}
+		g.levels[n.level-1] = append(g.levels[n.level-1], n)
+		if len(g.levels[n.level-1]) > 1 {
+			g.qualifier("sync")
+		}
+	}
+
+	// Import every package used by types before naming
+	for _, n := range g.order {
+		if _, err := g.typeString(n.typ); err != nil {
+			return fmt.Errorf("operation [%d]: %v", n.op.ID(), err)
+		}
+		if _, err := g.typeString(n.fnType); n.fnType != nil && err != nil {
+			return fmt.Errorf("operation [%d] %s: %v", n.op.ID(), n.op.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (g *generator) analyseFunc(n *node) error {
+	op := n.op
+	fn, err := g.r.Get(op.Name())
+	if err != nil {
+		return fmt.Errorf("operation [%d]: %v", op.ID(), err)
+	}
+	n.fnType = reflect.TypeOf(fn)
+	if n.fnType.NumOut() > 0 {
+		n.typ = n.fnType.Out(0)
+	}
+
+	nIn := n.fnType.NumIn()
+	inputs := op.Inputs()
+	if n.fnType.IsVariadic() && len(inputs) < nIn-1 || !n.fnType.IsVariadic() && len(inputs) != nIn {
+		return fmt.Errorf("operation [%d] %s: %v", op.ID(), op.Name(), flow.ErrInput)
+	}
+	for _, in := range inputs {
+		inNode := g.nodes[in]
+		if inNode.fnType != nil && inNode.typ == nil {
+			return fmt.Errorf("operation [%d] %s: input [%d] has no output", op.ID(), op.Name(), in.ID())
+		}
+		if inNode.level+1 > n.level {
+			n.level = inNode.level + 1
+		}
+	}
+
+	// Direct call if the function is reachable by name
+	pkg, name, ok := funcName(fn)
+	if ok && (isExported(name) && pkg != "main" || pkg == g.opt.PkgPath) {
+		if q := g.qualifier(pkg); q != "" {
+			name = q + "." + name
+		}
+		n.callee = name
+		return nil
+	}
+	for _, b := range g.binds {
+		if b.entry == op.Name() {
+			n.bind = b
+			return nil
+		}
+	}
+	n.bind = &bind{entry: op.Name(), typ: n.fnType}
+	g.binds = append(g.binds, n.bind)
+	g.qualifier("github.com/hexasoftware/flow/registry")
+	g.qualifier("fmt")
+	return nil
+}
+
+// name assigns identifiers once every import is known
+func (g *generator) name(ops []flow.Operation, outNames []string) {
+	for _, alias := range g.imports {
+		g.idents[alias] = true
+	}
+	for _, id := range []string{"err", "errs", "wg", "e", "fn", "ok", "r", g.opt.Func, "Bind" + g.opt.Func} {
+		g.idents[id] = true
+	}
+	prefix := strings.ToLower(g.opt.Func[:1]) + g.opt.Func[1:]
+	for _, b := range g.binds {
+		b.ident = g.ident(prefix + strings.ToUpper(b.entry[:1]) + b.entry[1:])
+	}
+	for _, n := range g.order {
+		switch n.op.Kind() {
+		case "in":
+			n.ident = g.ident("in" + n.op.Name())
+		case "input":
+			n.ident = g.ident(n.op.Name())
+		case "func":
+			if n.bind != nil {
+				n.callee = n.bind.ident
+			}
+			if n.typ != nil {
+				n.ident = g.ident(fmt.Sprintf("v%d", n.op.ID()))
+			}
+		}
+	}
+	for i := range ops {
+		name := "_"
+		if i < len(outNames) {
+			name = g.ident(outNames[i])
+		}
+		g.results = append(g.results, name)
+	}
+}
+
+// ident returns a unique identifier based on s
+func (g *generator) ident(s string) string {
+	b := []rune{}
+	for i, c := range s {
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b = append(b, '_')
+			}
+		default:
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	base := string(b)
+	if base == "" || isKeyword(base) {
+		base = "_" + base
+	}
+	id := base
+	for i := 1; g.idents[id]; i++ {
+		id = fmt.Sprintf("%s%d", base, i)
+	}
+	g.idents[id] = true
+	return id
+}
+
+func (g *generator) emit(ops []flow.Operation) ([]byte, error) {
+	body := bytes.NewBuffer(nil)
+
+	// Parameters, positional inputs first
+	params := []string{}
+	for _, kind := range []string{"in", "input"} {
+		nodes := []*node{}
+		for _, n := range g.order {
+			if n.op.Kind() == kind {
+				nodes = append(nodes, n)
+			}
+		}
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].index < nodes[j].index })
+		for _, n := range nodes {
+			typ, _ := g.typeString(n.typ)
+			params = append(params, n.ident+" "+typ)
+		}
+	}
+
+	// Results
+	results := []string{}
+	returns := []string{}
+	for i, op := range ops {
+		n := g.nodes[op]
+		if n.fnType != nil && n.typ == nil {
+			continue // executed for its side effects
+		}
+		typ, _ := g.typeString(n.typ)
+		results = append(results, g.results[i]+" "+typ)
+		returns = append(returns, g.expr(n))
+	}
+	results = append(results, "err error")
+	returns = append(returns, "nil")
+
+	// Result variables
+	vars := []string{}
+	for _, n := range g.order {
+		if n.op.Kind() != "func" || n.ident == "" {
+			continue
+		}
+		typ, _ := g.typeString(n.typ)
+		vars = append(vars, n.ident+" "+typ)
+	}
+
+	// Calls by level
+	for _, level := range g.levels {
+		if len(level) == 1 {
+			call, hasErr := g.call(level[0], "err")
+			fmt.Fprintf(body, "%s\n", call)
+			if hasErr {
+				fmt.Fprintf(body, "if err != nil {\nreturn\n}\n")
+			}
+			continue
+		}
+		nErrs := 0
+		calls := bytes.NewBuffer(nil)
+		for _, n := range level {
+			call, hasErr := g.call(n, fmt.Sprintf("errs[%d]", nErrs))
+			if hasErr {
+				nErrs++
+			}
+			fmt.Fprintf(calls, "go func() {\ndefer wg.Done()\n%s\n}()\n", call)
+		}
+		fmt.Fprintf(body, "{\nvar wg %s.WaitGroup\n", g.imports["sync"])
+		if nErrs > 0 {
+			fmt.Fprintf(body, "errs := make([]error, %d)\n", nErrs)
+		}
+		fmt.Fprintf(body, "wg.Add(%d)\n%swg.Wait()\n", len(level), calls)
+		if nErrs > 0 {
+			fmt.Fprintf(body, "for _, e := range errs {\nif e != nil {\nerr = e\nreturn\n}\n}\n")
+		}
+		fmt.Fprintf(body, "}\n")
+	}
+
+	out := bytes.NewBuffer(nil)
+	fmt.Fprintf(out, "// Code generated by flowgen. DO NOT EDIT.\n\npackage %s\n\n", g.opt.Package)
+	if len(g.imports) > 0 {
+		codegen.WriteImports(out, g.imports)
+		fmt.Fprintf(out, "\n")
+	}
+
+	if len(g.binds) > 0 {
+		fmt.Fprintf(out, "// Entries %s can't call directly, set by Bind%s\nvar (\n", g.opt.Func, g.opt.Func)
+		for _, b := range g.binds {
+			typ, _ := g.typeString(b.typ)
+			fmt.Fprintf(out, "%s %s\n", b.ident, typ)
+		}
+		fmt.Fprintf(out, ")\n\n")
+
+		fmt.Fprintf(out, "// Bind%s resolves the entries %s can't call directly from r,\n", g.opt.Func, g.opt.Func)
+		fmt.Fprintf(out, "// it must be called before %s\n", g.opt.Func)
+		fmt.Fprintf(out, "func Bind%s(r *%s.R) error {\n", g.opt.Func, g.imports["github.com/hexasoftware/flow/registry"])
+		fmt.Fprintf(out, "var fn interface{}\nvar err error\nvar ok bool\n")
+		for _, b := range g.binds {
+			typ, _ := g.typeString(b.typ)
+			fmt.Fprintf(out, "if fn, err = r.Get(%q); err != nil {\nreturn err\n}\n", b.entry)
+			fmt.Fprintf(out, "if %s, ok = fn.(%s); !ok {\n", b.ident, typ)
+			fmt.Fprintf(out, "return %s.Errorf(\"entry %%q: unexpected type %%T\", %q, fn)\n}\n", g.imports["fmt"], b.entry)
+		}
+		fmt.Fprintf(out, "return nil\n}\n\n")
+	}
+
+	fmt.Fprintf(out, "// %s generated from flow\n", g.opt.Func)
+	fmt.Fprintf(out, "func %s(%s) (%s) {\n", g.opt.Func, strings.Join(params, ", "), strings.Join(results, ", "))
+	if len(vars) > 0 {
+		fmt.Fprintf(out, "var (\n%s\n)\n", strings.Join(vars, "\n"))
+	}
+	out.Write(body.Bytes())
+	fmt.Fprintf(out, "return %s\n}\n", strings.Join(returns, ", "))
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("flowgen: %v\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// call returns the statement calling n, errDst receives the returned error
+func (g *generator) call(n *node, errDst string) (string, bool) {
+	args := []string{}
+	for i, in := range n.op.Inputs() {
+		inNode := g.nodes[in]
+		if in.Kind() == "const" && inNode.lit == "" {
+			typ, _ := g.typeString(paramType(n.fnType, i))
+			args = append(args, "*new("+typ+")")
+			continue
+		}
+		args = append(args, g.expr(inNode))
+	}
+	call := fmt.Sprintf("%s(%s)", n.callee, strings.Join(args, ", "))
+
+	nOut := n.fnType.NumOut()
+	if nOut == 0 {
+		return call, false
+	}
+	lhs := make([]string, nOut)
+	for i := range lhs {
+		lhs[i] = "_"
+	}
+	lhs[0] = n.ident
+	hasErr := nOut > 1 && n.fnType.Out(nOut-1) == errorType
+	if hasErr {
+		lhs[nOut-1] = errDst
+	}
+	return strings.Join(lhs, ", ") + " = " + call, hasErr
+}
+
+// expr returns the expression holding the result of n
+func (g *generator) expr(n *node) string {
+	switch n.op.Kind() {
+	case "alias":
+		return g.expr(g.nodes[n.op.Inputs()[0]])
+	case "const":
+		if n.lit == "" {
+			return "nil"
+		}
+		return n.lit
+	}
+	return n.ident
+}
+
+// qualifier returns the alias for the package path, importing it
+func (g *generator) qualifier(pkg string) string {
+	if pkg == g.opt.PkgPath {
+		return ""
+	}
+	if alias, ok := g.imports[pkg]; ok {
+		return alias
+	}
+	base := pkg[strings.LastIndex(pkg, "/")+1:]
+	b := []rune{}
+	for _, c := range base {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' && len(b) > 0 {
+			b = append(b, c)
+		}
+	}
+	base = string(b)
+	if base == "" || isKeyword(base) {
+		base = "pkg"
+	}
+	alias := base
+	for i := 1; ; i++ {
+		used := false
+		for _, a := range g.imports {
+			if a == alias {
+				used = true
+				break
+			}
+		}
+		if !used {
+			break
+		}
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	g.imports[pkg] = alias
+	return alias
+}
+
+// typeString returns the Go type expression of t
+func (g *generator) typeString(t reflect.Type) (string, error) {
+	if t == nil {
+		return "interface{}", nil
+	}
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if strings.Contains(t.Name(), "[") {
+			return "", fmt.Errorf("%w: generic type %v", ErrUnsupported, t)
+		}
+		if t.PkgPath() == "main" && g.opt.PkgPath != "main" {
+			return "", fmt.Errorf("%w: type %v of package main", ErrUnsupported, t)
+		}
+		q := g.qualifier(t.PkgPath())
+		if q == "" {
+			return t.Name(), nil
+		}
+		if !isExported(t.Name()) {
+			return "", fmt.Errorf("%w: unexported type %v", ErrUnsupported, t)
+		}
+		return q + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		elem, err := g.typeString(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		switch t.Kind() {
+		case reflect.Ptr:
+			return "*" + elem, nil
+		case reflect.Slice:
+			return "[]" + elem, nil
+		case reflect.Array:
+			return fmt.Sprintf("[%d]%s", t.Len(), elem), nil
+		}
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + elem, nil
+		case reflect.SendDir:
+			return "chan<- " + elem, nil
+		}
+		return "chan " + elem, nil
+	case reflect.Map:
+		key, err := g.typeString(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeString(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "map[" + key + "]" + elem, nil
+	case reflect.Func:
+		in := make([]string, t.NumIn())
+		for i := range in {
+			s, err := g.typeString(t.In(i))
+			if err != nil {
+				return "", err
+			}
+			if t.IsVariadic() && i == len(in)-1 {
+				s = "..." + s[2:]
+			}
+			in[i] = s
+		}
+		out := make([]string, t.NumOut())
+		for i := range out {
+			s, err := g.typeString(t.Out(i))
+			if err != nil {
+				return "", err
+			}
+			out[i] = s
+		}
+		ret := "func(" + strings.Join(in, ", ") + ")"
+		switch len(out) {
+		case 0:
+		case 1:
+			ret += " " + out[0]
+		default:
+			ret += " (" + strings.Join(out, ", ") + ")"
+		}
+		return ret, nil
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct{}", nil
+		}
+	}
+	return "", fmt.Errorf("%w: type %v", ErrUnsupported, t)
+}
+
+// literal returns the Go literal of v, typed literals are converted to the
+// value type when it isn't the default type of the constant
+func (g *generator) literal(v reflect.Value, typed bool) (string, error) {
+	t := v.Type()
+	var s string
+	switch t.Kind() {
+	case reflect.Bool:
+		s = fmt.Sprint(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = fmt.Sprint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = fmt.Sprint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != f || f > maxFloat || f < -maxFloat {
+			return "", fmt.Errorf("%w: const %v", ErrUnsupported, f)
+		}
+		s = fmt.Sprint(f)
+		if t.Kind() == reflect.Float32 {
+			s = fmt.Sprint(float32(f))
+		}
+	case reflect.String:
+		s = fmt.Sprintf("%q", v.String())
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return g.literal(v.Elem(), true)
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr:
+		return g.compositeLiteral(v)
+	default:
+		return "", fmt.Errorf("%w: const of type %v", ErrUnsupported, t)
+	}
+
+	def := t.PkgPath() == "" && (t.Kind() == reflect.Int || t.Kind() == reflect.String || t.Kind() == reflect.Bool)
+	if !typed || def {
+		return s, nil
+	}
+	typ, err := g.typeString(t)
+	if err != nil {
+		return "", err
+	}
+	return typ + "(" + s + ")", nil
+}
+
+func (g *generator) compositeLiteral(v reflect.Value) (string, error) {
+	t := v.Type()
+	typ, err := g.typeString(t)
+	if err != nil {
+		return "", err
+	}
+	if t.Kind() != reflect.Array && v.IsNil() {
+		return "(" + typ + ")(nil)", nil
+	}
+	if t.Kind() == reflect.Ptr {
+		return "", fmt.Errorf("%w: const of type %v", ErrUnsupported, t)
+	}
+	typedElem := t.Elem().Kind() == reflect.Interface
+
+	elems := []string{}
+	switch t.Kind() {
+	case reflect.Map:
+		typedKey := t.Key().Kind() == reflect.Interface
+		for _, k := range v.MapKeys() {
+			ks, err := g.literal(k, typedKey)
+			if err != nil {
+				return "", err
+			}
+			vs, err := g.literal(v.MapIndex(k), typedElem)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, ks+": "+vs)
+		}
+		sort.Strings(elems)
+	default:
+		for i := 0; i < v.Len(); i++ {
+			s, err := g.literal(v.Index(i), typedElem)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, s)
+		}
+	}
+	return typ + "{" + strings.Join(elems, ", ") + "}", nil
+}
+
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	maxFloat  = 1.7976931348623157e308
+)
+
+// paramType type of the i param of fn, the element type for variadics
+func paramType(fn reflect.Type, i int) reflect.Type {
+	if fn.IsVariadic() && i >= fn.NumIn()-1 {
+		return fn.In(fn.NumIn() - 1).Elem()
+	}
+	return fn.In(i)
+}
+
+// funcName package path and name of a package level func
+func funcName(fn interface{}) (pkg string, name string, ok bool) {
+	rf := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if rf == nil {
+		return "", "", false
+	}
+	full := rf.Name()
+	slash := strings.LastIndex(full, "/")
+	dot := strings.Index(full[slash+1:], ".")
+	if dot == -1 {
+		return "", "", false
+	}
+	pkg, name = full[:slash+1+dot], full[slash+1+dot+1:]
+	// closures, methods and generic instances
+	if strings.ContainsAny(name, ".()[]*") {
+		return "", "", false
+	}
+	return pkg, name, true
+}
+
+func isExported(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+func isKeyword(s string) bool {
+	switch s {
+	case "break", "case", "chan", "const", "continue", "default", "defer",
+		"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+		"interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var":
+		return true
+	}
+	return false
+}
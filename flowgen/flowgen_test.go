@@ -0,0 +1,151 @@
+package flowgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/flowgen"
+	"github.com/hexasoftware/flow/flowserver/flowbuilder"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func init() {
+	assert.Quiet = true
+}
+
+// typeCheck parses and type checks the generated source
+func typeCheck(t *testing.T, src []byte) *types.Package {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "gen.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("gen", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("type check: %v\n%s", err, src)
+	}
+	return pkg
+}
+
+func TestGenerate(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add(strings.Split, strings.Join, strings.Repeat, strings.ToUpper)
+	r.Add("cat", func(a, b string) string { return a + b })
+	r.Add("count", func(s []string) (int, error) { return len(s), nil })
+	f := flow.New().UseRegistry(r)
+
+	parts := f.Op("Split", f.In(0), ",")
+	joined := f.Op("Join", parts, f.Op("ToUpper", f.Input("sep", nil, nil)))
+	f.Output("joined", joined)
+	f.Output("count", f.Op("count", parts))
+	f.Output("cat", f.Op("cat", joined, f.Op("Repeat", "x", 3)))
+
+	buf := bytes.NewBuffer(nil)
+	err := flowgen.Generate(buf, f, r, flowgen.Options{Package: "gen"})
+	a.Eq(err, nil, "generate should not error")
+
+	pkg := typeCheck(t, buf.Bytes())
+	run := pkg.Scope().Lookup("Run")
+	a.NotEq(run, nil, "should generate Run")
+	a.Eq(run.Type().String(), "func(in0 string, sep string) (joined string, count int, cat string, err error)", "signature should be typed")
+	a.NotEq(pkg.Scope().Lookup("BindRun"), nil, "closures should be bound")
+
+	src := buf.String()
+	a.Eq(strings.Contains(src, "strings.Split(in0, \",\")"), true, "should call functions directly")
+	a.Eq(strings.Contains(src, "go func()"), true, "should run independent calls in goroutines")
+}
+
+func TestGenerateTypeError(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add(strings.Join, strings.ToUpper)
+	f := flow.New().UseRegistry(r)
+
+	op := f.Op("Join", f.Op("ToUpper", "a"), ",") // string instead of []string
+
+	buf := bytes.NewBuffer(nil)
+	err := flowgen.Generate(buf, f, r, flowgen.Options{Package: "gen"}, op)
+	a.Eq(err, nil, "generate should not error")
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "gen.go", buf.Bytes(), 0)
+	a.Eq(err, nil, "should parse")
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("gen", fset, []*ast.File{file}, nil)
+	a.NotEq(err, nil, "graph type errors should not compile")
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add(strings.ToUpper)
+	f := flow.New().UseRegistry(r)
+
+	err := flowgen.Generate(bytes.NewBuffer(nil), f, r, flowgen.Options{})
+	a.Eq(err, flowgen.ErrNoOutput, "should error without outputs")
+
+	op := f.Op("ToUpper", f.Var("v", "a"))
+	err = flowgen.Generate(bytes.NewBuffer(nil), f, r, flowgen.Options{}, op)
+	a.NotEq(err, nil, "vars should not be supported")
+}
+
+func TestGenerateDocument(t *testing.T) {
+	a := assert.A(t)
+	doc := &flowbuilder.FlowDocument{
+		Nodes: []flowbuilder.Node{
+			{ID: "in", Src: "Input", Prop: map[string]string{"input name": "text"}},
+			{ID: "split", Src: "Split", DefaultInputs: map[int]string{1: ","}},
+			{ID: "join", Src: "Join", DefaultInputs: map[int]string{1: "-"}},
+		},
+		Links: []flowbuilder.Link{
+			{From: "in", To: "split", In: 0},
+			{From: "split", To: "join", In: 0},
+		},
+	}
+	r := registry.New()
+	r.Add(strings.Split, strings.Join)
+	buf := bytes.NewBuffer(nil)
+	err := flowgen.GenerateDocument(buf, doc, r, flowgen.Options{Package: "gen", Func: "Dashes"})
+	a.Eq(err, nil, "generate should not error")
+
+	pkg := typeCheck(t, buf.Bytes())
+	fn := pkg.Scope().Lookup("Dashes")
+	a.NotEq(fn, nil, "should generate Dashes")
+	a.Eq(fn.Type().String(), "func(text string) (_ string, err error)", "should use sink node as output")
+}
+
+func TestGenerateJSON(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add(strings.Split, strings.Join)
+
+	f := flow.New().UseRegistry(r)
+	text := f.Input("text", reflect.TypeOf(""), nil)
+	f.Output("dashes", f.Op("Join", f.Op("Split", text, ","), "-"))
+	data, err := json.Marshal(f)
+	a.Eq(err, nil, "should marshal")
+
+	buf := bytes.NewBuffer(nil)
+	err = flowgen.GenerateJSON(buf, data, r, flowgen.Options{Package: "gen", Func: "Dashes"})
+	a.Eq(err, nil, "generate should not error")
+
+	pkg := typeCheck(t, buf.Bytes())
+	fn := pkg.Scope().Lookup("Dashes")
+	a.NotEq(fn, nil, "should generate Dashes")
+	a.Eq(fn.Type().String(), "func(text string) (dashes string, err error)", "should use the named outputs")
+
+	err = flowgen.GenerateJSON(bytes.NewBuffer(nil), []byte(`{"operations": []}`), r, flowgen.Options{})
+	a.Eq(err, flowgen.ErrNoOutput, "should error without outputs")
+}
@@ -98,6 +98,8 @@ func (fb *FlowBuilder) Build(ID string) flow.Operation {
 
 	var op flow.Operation
 	var inputs []reflect.Type
+	var entry *registry.Entry
+	var config []flow.Data
 
 	switch node.Src {
 	case "Portal From":
@@ -111,6 +113,18 @@ func (fb *FlowBuilder) Build(ID string) flow.Operation {
 		fb.OperationMap[node.ID] = op
 		return op
 	case "Input":
+		if name := node.Prop["input name"]; name != "" {
+			def, err := parseValue(nil, nil, node.Prop["default"])
+			if err != nil {
+				op := f.ErrOp(err)
+				fb.OperationMap[node.ID] = op
+				return op
+			}
+			op := f.Input(name, nil, def)
+			fb.OperationMap[node.ID] = op
+			return op
+		}
+		// Deprecated: positional input
 		inputID, err := strconv.Atoi(node.Prop["input"])
 		if err != nil {
 			op := f.ErrOp(errors.New("Invalid inputID value, must be a number"))
@@ -129,15 +143,23 @@ func (fb *FlowBuilder) Build(ID string) flow.Operation {
 		var t interface{}
 		inputs = []reflect.Type{reflect.TypeOf(t)}
 	default:
-		log.Println("Loading entry:", node.Src)
-		entry, err := r.Entry(node.Src)
+		log.Println("Loading entry:", node.EntryName())
+		e, err := r.Entry(node.EntryName())
 		if err != nil {
 			op = f.ErrOp(err)
 			fb.OperationMap[node.ID] = op
 			return op
 		}
+		entry = e
 		inputs = entry.Inputs
-
+		if entry.Factory() {
+			config, err = paramsFor(node, entry)
+			if err != nil {
+				op = f.ErrOp(err)
+				fb.OperationMap[node.ID] = op
+				return op
+			}
+		}
 	}
 
 	//// Build inputs ////
@@ -146,7 +168,15 @@ func (fb *FlowBuilder) Build(ID string) flow.Operation {
 		l := doc.FetchLinkTo(node.ID, i)
 		if l == nil { // No link we fetch the value inserted
 			// Direct input entries
-			v, err := parseValue(inputs[i], node.DefaultInputs[i])
+			var schema *registry.Schema
+			if entry != nil && i < len(entry.Description.Inputs) {
+				schema = entry.Description.Inputs[i].Schema
+			}
+			v, err := parseValue(inputs[i], schema, node.DefaultInputs[i])
+			if err == nil && v == nil && entry != nil {
+				// No value, fallback to the entry default
+				v, err = entry.DefaultInput(i)
+			}
 			if err != nil {
 				param[i] = f.ErrOp(err)
 				continue
@@ -164,7 +194,11 @@ func (fb *FlowBuilder) Build(ID string) flow.Operation {
 	case "SetVar":
 		op = f.SetVar(node.Prop["variable name"], param[0])
 	default:
-		op = f.Op(node.Src, param...)
+		if entry.Factory() {
+			op = f.Factory(node.EntryName(), config, param...)
+			break
+		}
+		op = f.Op(node.EntryName(), param...)
 	}
 
 	fb.OperationMap[node.ID] = op
@@ -208,13 +242,53 @@ func (fb *FlowBuilder) buildTriggersFor(node *Node, targetOp flow.Operation) err
 	return nil
 }
 
+// Optimize runs the flow optimizer for the operations built from node IDs
+func (fb *FlowBuilder) Optimize(IDs ...string) flow.OptimizeStats {
+	ops := []flow.Operation{}
+	for _, ID := range IDs {
+		if op, ok := fb.OperationMap[ID]; ok {
+			ops = append(ops, op)
+		}
+	}
+	return fb.flow.Optimize(ops...)
+}
+
 // Flow returns the build flow
 func (fb *FlowBuilder) Flow() *flow.Flow {
 	return fb.flow
 }
 
-// Or give a string
-func parseValue(typ reflect.Type, raw string) (flow.Data, error) {
+// paramProp the node prop holding param i of a factory entry, the param
+// name or "param i" if unnamed
+func paramProp(entry *registry.Entry, i int) string {
+	if i < len(entry.Description.Params) && entry.Description.Params[i].Name != "" {
+		return entry.Description.Params[i].Name
+	}
+	return fmt.Sprintf("param %d", i)
+}
+
+// paramsFor parses the factory params of node from its props, missing
+// params are left nil to use the entry defaults
+func paramsFor(node *Node, entry *registry.Entry) ([]flow.Data, error) {
+	config := make([]flow.Data, len(entry.Params))
+	for i, typ := range entry.Params {
+		var schema *registry.Schema
+		if i < len(entry.Description.Params) {
+			schema = entry.Description.Params[i].Schema
+		}
+		prop := paramProp(entry, i)
+		v, err := parseValue(typ, schema, node.Prop[prop])
+		if err != nil {
+			return nil, fmt.Errorf("param '%s': %v", prop, err)
+		}
+		config[i] = v
+	}
+	return config, nil
+}
+
+// parseValue parses a default input, raw is JSON except for string and int
+// types, values are validated against the input schema if any
+func parseValue(typ reflect.Type, schema *registry.Schema, raw string) (flow.Data, error) {
 
 	if len(raw) == 0 {
 		return nil, nil
@@ -235,14 +309,23 @@ func parseValue(typ reflect.Type, raw string) (flow.Data, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := schema.Validate(float64(v)); err != nil {
+			return nil, err
+		}
 		ret = v
 	case reflect.String:
+		if err := schema.Validate(raw); err != nil {
+			return nil, err
+		}
 		ret = raw
 	default:
 		if len(raw) == 0 {
 			return nil, nil
 		}
 		//ret = reflect.Zero(typ)
+		if err := schema.ValidateJSON([]byte(raw)); err != nil {
+			return nil, err
+		}
 
 		refVal := reflect.New(typ)
 		err := json.Unmarshal([]byte(raw), refVal.Interface())
@@ -1,5 +1,7 @@
 package flowbuilder
 
+import "fmt"
+
 // Node flow-ui node representation
 type Node struct {
 	ID            string            `json:"id"`
@@ -7,6 +9,16 @@ type Node struct {
 	Label         string            `json:"label"`
 	DefaultInputs map[int]string    `json:"defaultInputs"`
 	Prop          map[string]string `json:"prop"`
+	// Version of the registry entry the node was saved with, 0 for latest
+	Version int `json:"version,omitempty"`
+}
+
+// EntryName registry name of the node entry including the pinned version
+func (n *Node) EntryName() string {
+	if n.Version == 0 {
+		return n.Src
+	}
+	return fmt.Sprintf("%s@%d", n.Src, n.Version)
 }
 
 // Link that joins two nodes
@@ -95,3 +107,19 @@ func (fd *FlowDocument) FetchNamedPortalIn(name string) *Node {
 	}
 	return nil
 }
+
+// Sinks IDs of the nodes without outgoing links
+func (fd *FlowDocument) Sinks() []string {
+	linked := map[string]bool{}
+	for _, l := range fd.Links {
+		linked[l.From] = true
+	}
+	ret := []string{}
+	for _, n := range fd.Nodes {
+		if linked[n.ID] || n.Src == "Portal In" {
+			continue
+		}
+		ret = append(ret, n.ID)
+	}
+	return ret
+}
@@ -0,0 +1,89 @@
+package flowbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// Incompatibility a node referencing an entry version no longer registered
+type Incompatibility struct {
+	NodeID  string `json:"nodeId"`
+	Src     string `json:"src"`
+	Version int    `json:"version"`
+	// Latest registered version of the entry, 0 if the entry is gone
+	Latest int `json:"latest"`
+}
+
+func (i Incompatibility) String() string {
+	if i.Latest == 0 {
+		return fmt.Sprintf("node [%s]: entry '%s' is not registered", i.NodeID, i.Src)
+	}
+	return fmt.Sprintf("node [%s]: '%s' version %d is not registered, latest is %d", i.NodeID, i.Src, i.Version, i.Latest)
+}
+
+// Compat reports the nodes of the document referencing entries or
+// versions not registered in r
+func (fd *FlowDocument) Compat(r *registry.R) []Incompatibility {
+	ret := []Incompatibility{}
+	for _, n := range fd.Nodes {
+		if isBuiltin(n.Src) {
+			continue
+		}
+		if _, err := r.Entry(n.EntryName()); err == nil {
+			continue
+		}
+		latest := 0
+		if e, err := r.Entry(n.Src); err == nil {
+			latest = e.Version
+		}
+		ret = append(ret, Incompatibility{n.ID, n.Src, n.Version, latest})
+	}
+	return ret
+}
+
+// Pin records the registry entry version used by each node of the raw
+// document, nodes keep the version pinned in prevDoc if they are unchanged,
+// fields unknown to FlowDocument are preserved
+func Pin(rawDoc, prevDoc []byte, r *registry.R) ([]byte, error) {
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(rawDoc, &doc); err != nil {
+		return nil, err
+	}
+	prev := &FlowDocument{}
+	if len(prevDoc) > 0 {
+		if err := json.Unmarshal(prevDoc, prev); err != nil {
+			prev = &FlowDocument{}
+		}
+	}
+	nodes, _ := doc["nodes"].([]interface{})
+	for _, rn := range nodes {
+		n, ok := rn.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		src, _ := n["src"].(string)
+		if v, _ := n["version"].(float64); v > 0 || isBuiltin(src) {
+			continue
+		}
+		id, _ := n["id"].(string)
+		if pn := prev.FetchNodeByID(id); pn != nil && pn.Src == src && pn.Version > 0 {
+			n["version"] = pn.Version
+			continue
+		}
+		if e, err := r.Entry(src); err == nil {
+			n["version"] = e.Version
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// isBuiltin nodes handled by the builder without a registry entry
+func isBuiltin(src string) bool {
+	switch src {
+	case "Input", "Var", "SetVar", "Portal From", "Portal In":
+		return true
+	}
+	return false
+}
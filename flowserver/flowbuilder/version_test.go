@@ -0,0 +1,77 @@
+package flowbuilder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hexasoftware/flow/flowserver/flowbuilder"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestPin(t *testing.T) {
+	r := registry.New()
+	r.Add("scale", func(a float64) float64 { return a * 2 }).Version(3)
+	r.Add("neg", func(a float64) float64 { return -a })
+	tests := []struct {
+		name string
+		node string
+		prev string
+		want int
+	}{
+		{"new node", `{"id": "n", "src": "scale"}`, ``, 3},
+		{"unchanged node", `{"id": "n", "src": "scale"}`, `{"id": "n", "src": "scale", "version": 2}`, 2},
+		{"changed src", `{"id": "n", "src": "neg"}`, `{"id": "n", "src": "scale", "version": 2}`, 1},
+		{"pinned node", `{"id": "n", "src": "scale", "version": 1}`, `{"id": "n", "src": "scale", "version": 2}`, 1},
+		{"unknown entry", `{"id": "n", "src": "missing"}`, ``, 0},
+		{"builtin node", `{"id": "n", "src": "Input"}`, ``, 0},
+	}
+	for _, tt := range tests {
+		a := assert.A(t)
+		prev := []byte{}
+		if tt.prev != "" {
+			prev = []byte(`{"nodes": [` + tt.prev + `]}`)
+		}
+		raw, err := flowbuilder.Pin([]byte(`{"nodes": [`+tt.node+`], "extra": true}`), prev, r)
+		a.Eq(err, nil, tt.name+": should pin")
+
+		doc := struct {
+			flowbuilder.FlowDocument
+			Extra bool `json:"extra"`
+		}{}
+		a.Eq(json.Unmarshal(raw, &doc), nil, tt.name+": should be a document")
+		a.Eq(doc.Nodes[0].Version, tt.want, tt.name+": version")
+		a.Eq(doc.Extra, true, tt.name+": should keep unknown fields")
+	}
+}
+
+func TestCompat(t *testing.T) {
+	r := registry.New()
+	r.Add("scale", func(a float64) float64 { return a * 2 }).Version(3)
+	tests := []struct {
+		name string
+		node flowbuilder.Node
+		want []flowbuilder.Incompatibility
+	}{
+		{"registered version", flowbuilder.Node{ID: "n", Src: "scale", Version: 3}, nil},
+		{"latest version", flowbuilder.Node{ID: "n", Src: "scale"}, nil},
+		{"removed version", flowbuilder.Node{ID: "n", Src: "scale", Version: 1},
+			[]flowbuilder.Incompatibility{{NodeID: "n", Src: "scale", Version: 1, Latest: 3}}},
+		{"removed entry", flowbuilder.Node{ID: "n", Src: "missing", Version: 2},
+			[]flowbuilder.Incompatibility{{NodeID: "n", Src: "missing", Version: 2, Latest: 0}}},
+		{"builtin node", flowbuilder.Node{ID: "n", Src: "Input"}, nil},
+	}
+	for _, tt := range tests {
+		a := assert.A(t)
+		doc := &flowbuilder.FlowDocument{Nodes: []flowbuilder.Node{tt.node}}
+		got := doc.Compat(r)
+		if tt.want == nil {
+			a.Eq(len(got), 0, tt.name+": should be compatible")
+			continue
+		}
+		a.Eq(got, tt.want, tt.name+": incompatibilities")
+	}
+	a := assert.A(t)
+	inc := flowbuilder.Incompatibility{NodeID: "n", Src: "scale", Version: 1, Latest: 3}
+	a.Eq(inc.String(), "node [n]: 'scale' version 1 is not registered, latest is 3", "should describe the incompatibility")
+}
@@ -1,4 +1,14 @@
 package flowserver
 
+import (
+	"reflect"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
 //Base64Data simple data to output base64 dataurl
 type Base64Data string
+
+func init() {
+	registry.RegisterSchema(reflect.TypeOf(Base64Data("")), &registry.Schema{Type: "string", Format: "data-url"})
+}
@@ -0,0 +1,13 @@
+package flowserver
+
+import "github.com/hexasoftware/flow/metrics"
+
+// flowserver gauges, exposed by metrics.Handler()
+var (
+	activeSessions = metrics.Default.NewGauge("flowserver_sessions_active",
+		"Number of flow sessions loaded in memory")
+	connectedClients = metrics.Default.NewGauge("flowserver_clients_connected",
+		"Number of connected websocket clients")
+	runningFlows = metrics.Default.NewGauge("flowserver_flows_running",
+		"Number of flows currently running")
+)
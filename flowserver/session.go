@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -41,8 +42,9 @@ type FlowSession struct {
 	nodeActivity map[string]*NodeActivity
 
 	Data    map[interface{}]interface{}
-	flow    *flow.Flow
-	running bool
+	running int  // number of flows running in this session
+	dirty   bool // document updated since it was saved
+	refs    int  // connections using the session, guarded by the manager
 }
 
 //NewSession creates and initializes a NewSession
@@ -73,8 +75,6 @@ func NewSession(fsm *FlowSessionManager, ID string) *FlowSession {
 		nodeActivity: map[string]*NodeActivity{},
 		// Experimental
 		Data: map[interface{}]interface{}{},
-
-		flow: nil,
 	}
 	return s
 }
@@ -104,6 +104,16 @@ func (s *FlowSession) ClientAdd(c *websocket.Conn) error {
 	if err != nil {
 		return err
 	}
+	// Warn about nodes using entries no longer registered
+	doc := &flowbuilder.FlowDocument{}
+	if json.Unmarshal(s.RawDoc, doc) == nil {
+		for _, inc := range doc.Compat(s.manager.registry) {
+			err = c.WriteJSON(SendMessage{OP: "sessionNotify", Data: inc.String()})
+			if err != nil {
+				return err
+			}
+		}
+	}
 
 	// Sending activity
 	return c.WriteJSON(s.activity())
@@ -122,10 +132,6 @@ func (s *FlowSession) ClientRemove(c *websocket.Conn) {
 		}
 	}
 	s.Chat.ClientRemove(c)
-	/*if len(s.clients) == 0 && s.flow == nil {
-		log.Println("No more clients, remove session")
-		delete(s.mgr.sessions, s.ID) // Clear memory session
-	}*/
 }
 
 // ChatJoin the chat room on this session
@@ -138,8 +144,8 @@ func (s *FlowSession) DocumentUpdate(c *websocket.Conn, data []byte) error {
 	s.Lock()
 	defer s.Unlock()
 
-	s.RawDoc = make([]byte, len(data))
-	copy(s.RawDoc, data)
+	s.setDoc(data)
+	s.dirty = true
 
 	return s.broadcast(c, SendMessage{OP: "document", Data: json.RawMessage(s.RawDoc)})
 }
@@ -149,8 +155,7 @@ func (s *FlowSession) DocumentSave(data []byte) error {
 	s.Lock()
 	defer s.Unlock()
 
-	s.RawDoc = make([]byte, len(data))
-	copy(s.RawDoc, data)
+	s.setDoc(data)
 
 	fpath, err := s.manager.pathFor(s.ID)
 	if err != nil {
@@ -163,11 +168,23 @@ func (s *FlowSession) DocumentSave(data []byte) error {
 		log.Println("writing file", err)
 		return err
 	}
+	s.dirty = false
 
 	s.notify("Session saved")
 	return s.broadcast(nil, SendMessage{OP: "documentSave", Data: "saved"})
 }
 
+// setDoc sets the session document pinning the entry versions of the nodes
+func (s *FlowSession) setDoc(data []byte) {
+	pinned, err := flowbuilder.Pin(data, s.RawDoc, s.manager.registry)
+	if err != nil {
+		log.Println("Unable to pin document versions:", err)
+		pinned = make([]byte, len(data))
+		copy(pinned, data)
+	}
+	s.RawDoc = pinned
+}
+
 // Document send document to client c
 func (s *FlowSession) Document(c *websocket.Conn) error {
 	s.Lock()
@@ -176,15 +193,37 @@ func (s *FlowSession) Document(c *websocket.Conn) error {
 	return c.WriteJSON(SendMessage{OP: "document", Data: json.RawMessage(s.RawDoc)})
 }
 
+// runMode how nodeProcess records runs
+type runMode int
+
+const (
+	runPlain runMode = iota
+	runRecord
+	runReplay
+)
+
+// MaxRecordSize bytes recorded per run, executions past it are not
+// recorded
+var MaxRecordSize int64 = 64 << 20
+
 // NodeProcess a node triggering results
 // Build a flow and run
 func (s *FlowSession) NodeProcess(c *websocket.Conn, data []byte) error {
+	return s.nodeProcess(data, runPlain)
+}
 
-	if s.flow != nil {
-		s.Notify("flow is already running")
-		return errors.New("nodes already running")
-	}
+// NodeRecord runs nodes recording the run in the session store, replacing
+// the previous recording
+func (s *FlowSession) NodeRecord(c *websocket.Conn, data []byte) error {
+	return s.nodeProcess(data, runRecord)
+}
 
+// NodeReplay runs nodes replaying the results of the last recorded run
+func (s *FlowSession) NodeReplay(c *websocket.Conn, data []byte) error {
+	return s.nodeProcess(data, runReplay)
+}
+
+func (s *FlowSession) nodeProcess(data []byte, mode runMode) error {
 	ids := []string{}
 	err := json.Unmarshal(data, &ids)
 	if err != nil {
@@ -194,9 +233,7 @@ func (s *FlowSession) NodeProcess(c *websocket.Conn, data []byte) error {
 	// *New* 25-02-2018 node Array
 	//ID := string(data[1 : len(data)-1]) // remove " instead of unmarshalling json
 
-	// Clear activity
-	s.nodeActivity = map[string]*NodeActivity{}
-	s.Broadcast(nil, s.activity()) // Empty activity in clients
+	s.clearActivity()
 
 	build := func() error {
 
@@ -232,22 +269,41 @@ func (s *FlowSession) NodeProcess(c *websocket.Conn, data []byte) error {
 			return builder.Err
 		}
 
-		s.flow = builder.Flow()
-		log.Println("Flow:", s.flow)
+		f := builder.Flow()
+		log.Println("Flow:", f)
 
 		log.Println("Experimental: Loading data")
-		for k, v := range s.Data {
-			s.flow.Data.Store(k, v)
-		}
+		s.loadData(f)
 
-		defer func() { // After routing gone
-			s.flow = nil
-		}()
+		recPath, err := s.manager.pathFor(s.ID + ".rec")
+		if err != nil {
+			return err
+		}
+		switch mode {
+		case runReplay:
+			rec, err := loadRecording(recPath)
+			if err != nil {
+				return err
+			}
+			f.UseReplay(rec)
+		case runRecord:
+			// each run records in its own file, the last finished run
+			// replaces the previous one
+			recFile, err := os.CreateTemp(filepath.Dir(recPath), filepath.Base(recPath)+".*")
+			if err != nil {
+				return err
+			}
+			defer func() {
+				recFile.Close()
+				e(os.Rename(recFile.Name(), recPath))
+			}()
+			f.UseRecorder(flow.NewRecorder(&limitWriter{recFile, MaxRecordSize}))
+		}
 
 		// Flow hooks
 		// Flow activity TODO: needs improvements as it shouldn't send the overall activity to client
 		// instead should send singular events
-		s.flow.Hook(flow.Hook{
+		f.Hook(flow.Hook{
 			Any: func(name string, hookOp flow.Operation, triggerTime time.Time, extra ...flow.Data) {
 				s.Lock()
 				defer s.Unlock()
@@ -311,7 +367,7 @@ func (s *FlowSession) NodeProcess(c *websocket.Conn, data []byte) error {
 			return fmt.Errorf("Operation not found %v", ID)
 		}*/
 		log.Println("Processing operation")
-		sess := s.flow.NewSession()
+		sess := f.NewSession()
 		_, err = sess.Run(ops...)
 		if err != nil {
 			log.Println("Error operation", err)
@@ -319,18 +375,16 @@ func (s *FlowSession) NodeProcess(c *websocket.Conn, data []byte) error {
 		}
 
 		log.Println("Experimental storing data to session")
-		// Copy Data from flow
-		s.flow.Data.Range(func(k, v interface{}) bool {
-			s.Data[k] = v
-			return true
-		})
+		s.storeData(f)
 		log.Println("Operation finish")
-		log.Println("Flow now:", s.flow)
+		log.Println("Flow now:", f)
 		return nil
 	}
 
 	// Background running
 	go func() {
+		s.runStart()
+		defer s.runEnd()
 		err := build()
 		if err != nil {
 			s.Notify(fmt.Sprint("ERR:", err))
@@ -344,14 +398,8 @@ func (s *FlowSession) NodeProcess(c *websocket.Conn, data []byte) error {
 // this is for the demo purposes
 func (s *FlowSession) NodeTrain(c *websocket.Conn, data []byte) error {
 	ID := string(data[1 : len(data)-1]) // remove " instead of unmarshalling json
-	if s.flow != nil {
-		s.Notify("a node is already running")
-		return errors.New("node already running")
-	}
 
-	// Clear activity
-	s.nodeActivity = map[string]*NodeActivity{}
-	s.Broadcast(nil, s.activity()) // Ampty activity
+	s.clearActivity()
 
 	build := func() error {
 		localR := s.manager.registry.Clone()
@@ -377,18 +425,12 @@ func (s *FlowSession) NodeTrain(c *websocket.Conn, data []byte) error {
 			return builder.Err
 		}
 
-		s.flow = builder.Flow()
-		log.Println("Flow:", s.flow)
+		f := builder.Flow()
+		log.Println("Flow:", f)
 
 		// XXX: Possibly remove
 		log.Println("Experimental: Loading global data")
-		for k, v := range s.Data {
-			s.flow.Data.Store(k, v)
-		}
-
-		defer func() { // After routing gone
-			s.flow = nil
-		}()
+		s.loadData(f)
 		// Flow activity
 
 		op, ok := builder.OperationMap[ID]
@@ -414,19 +456,17 @@ func (s *FlowSession) NodeTrain(c *websocket.Conn, data []byte) error {
 				}
 			}
 		}
-		fmt.Fprintf(s, "%v", s.flow)
-		// Copy Data from flow
-		s.flow.Data.Range(func(k, v interface{}) bool {
-			s.Data[k] = v
-			return true
-		})
+		fmt.Fprintf(s, "%v", f)
+		s.storeData(f)
 		log.Println("Operation finish")
-		log.Println("Flow now:", s.flow)
+		log.Println("Flow now:", f)
 		return nil
 	}
 
 	// Parallel building
 	go func() {
+		s.runStart()
+		defer s.runEnd()
 		err := build()
 		if err != nil {
 			s.Notify(fmt.Sprint("ERR:", err))
@@ -437,6 +477,79 @@ func (s *FlowSession) NodeTrain(c *websocket.Conn, data []byte) error {
 
 }
 
+// clearActivity clears node activity in clients unless other flows are running
+func (s *FlowSession) clearActivity() {
+	s.Lock()
+	defer s.Unlock()
+	if s.running > 0 {
+		return
+	}
+	s.nodeActivity = map[string]*NodeActivity{}
+	s.broadcast(nil, s.activity()) // Empty activity in clients
+}
+
+func (s *FlowSession) runStart() {
+	s.Lock()
+	defer s.Unlock()
+	s.running++
+	runningFlows.Inc()
+}
+
+func (s *FlowSession) runEnd() {
+	s.Lock()
+	s.running--
+	runningFlows.Dec()
+	s.Unlock()
+
+	s.manager.Lock()
+	defer s.manager.Unlock()
+	s.manager.dropIdle(s)
+}
+
+// loadData loads session data into flow f
+func (s *FlowSession) loadData(f *flow.Flow) {
+	s.Lock()
+	defer s.Unlock()
+	for k, v := range s.Data {
+		f.Data.Store(k, v)
+	}
+}
+
+// storeData copy Data from flow f
+func (s *FlowSession) storeData(f *flow.Flow) {
+	s.Lock()
+	defer s.Unlock()
+	f.Data.Range(func(k, v interface{}) bool {
+		s.Data[k] = v
+		return true
+	})
+}
+
+// limitWriter rejects writes once n bytes would be exceeded, records are
+// written whole so a record is either written or dropped
+type limitWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, errors.New("record size limit reached")
+	}
+	n, err := l.w.Write(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+func loadRecording(fpath string) (*flow.Recording, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return flow.LoadRecording(f)
+}
+
 func (s *FlowSession) activity() *SendMessage {
 
 	msg := SendMessage{OP: "nodeActivity",
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/hexasoftware/flow"
 	"github.com/hexasoftware/flow/registry"
@@ -25,16 +26,54 @@ type FlowSessionManager struct {
 	sessions map[string]*FlowSession
 	chats    map[string]*ChatRoom
 
+	registryPush *time.Timer // pending registry push to clients
+
 	sync.Mutex
 }
 
+// registryPushDelay batches registry changes before pushing to clients
+const registryPushDelay = 100 * time.Millisecond
+
 //NewFlowSessionManager creates a New initialized FlowSessionManager
 func NewFlowSessionManager(r *registry.R, store string) *FlowSessionManager {
-	return &FlowSessionManager{
+	fsm := &FlowSessionManager{
 		registry: r,
 		store:    store,
 		sessions: map[string]*FlowSession{},
 	}
+	r.Watch(fsm.registryChanged)
+	return fsm
+}
+
+// registryChanged schedules a registry push to connected clients
+func (fsm *FlowSessionManager) registryChanged(registry.Event) {
+	fsm.Lock()
+	defer fsm.Unlock()
+	if fsm.registryPush != nil {
+		return
+	}
+	fsm.registryPush = time.AfterFunc(registryPushDelay, fsm.pushRegistry)
+}
+
+// pushRegistry sends the current registry to every session client
+func (fsm *FlowSessionManager) pushRegistry() {
+	fsm.Lock()
+	fsm.registryPush = nil
+	sessions := make([]*FlowSession, 0, len(fsm.sessions))
+	for _, s := range fsm.sessions {
+		sessions = append(sessions, s)
+	}
+	fsm.Unlock()
+
+	desc, err := fsm.registry.Descriptions()
+	if e(err) {
+		return
+	}
+	for _, s := range sessions {
+		s.Lock()
+		e(s.broadcast(nil, SendMessage{OP: "registry", Data: desc}))
+		s.Unlock()
+	}
 }
 
 //CreateSession creates a new session
@@ -47,6 +86,8 @@ func (fsm *FlowSessionManager) CreateSession() *FlowSession {
 		if !ok {
 			sess = NewSession(fsm, ID)
 			fsm.sessions[ID] = sess // XXX: Make this sync
+			activeSessions.Inc()
+			sess.refs++
 			return sess
 		}
 	}
@@ -63,11 +104,37 @@ func (fsm *FlowSessionManager) LoadSession(ID string) (*FlowSession, error) {
 	if !ok {
 		sess = NewSession(fsm, ID)
 		fsm.sessions[ID] = sess // Make this sync
+		activeSessions.Inc()
 	}
+	sess.refs++
 	return sess, nil
 
 }
 
+// ReleaseSession releases a session obtained with CreateSession or
+// LoadSession, the session is dropped from memory once it is not used
+func (fsm *FlowSessionManager) ReleaseSession(sess *FlowSession) {
+	fsm.Lock()
+	defer fsm.Unlock()
+	sess.refs--
+	fsm.dropIdle(sess)
+}
+
+// dropIdle removes sess from memory if no client uses it, no flow is
+// running and it has no unsaved document or data, fsm must be locked
+func (fsm *FlowSessionManager) dropIdle(sess *FlowSession) {
+	if sess.refs > 0 || fsm.sessions[sess.ID] != sess {
+		return
+	}
+	sess.Lock()
+	defer sess.Unlock()
+	if sess.running > 0 || sess.dirty || len(sess.Data) > 0 {
+		return
+	}
+	delete(fsm.sessions, sess.ID)
+	activeSessions.Dec()
+}
+
 var upgrader = websocket.Upgrader{}
 
 func (fsm *FlowSessionManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -80,6 +147,9 @@ func (fsm *FlowSessionManager) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	}
 	defer c.Close()
 
+	connectedClients.Inc()
+	defer connectedClients.Dec()
+
 	// Room
 	var sess *FlowSession
 	defer func() {
@@ -88,6 +158,7 @@ func (fsm *FlowSessionManager) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		}
 		// Remove client on exit
 		sess.ClientRemove(c)
+		fsm.ReleaseSession(sess)
 	}()
 
 	/////////////////
@@ -130,6 +201,8 @@ func (fsm *FlowSessionManager) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 				if sess != nil {
 					sess.ClientRemove(c)
+					fsm.ReleaseSession(sess)
+					sess = nil
 				}
 				sess, err = fsm.LoadSession(sessID) // Set our session
 				if e(err) {
@@ -165,6 +238,16 @@ func (fsm *FlowSessionManager) ServeHTTP(w http.ResponseWriter, r *http.Request)
 					return errors.New("nodeRun: invalid session")
 				}
 				return sess.NodeProcess(c, m.Data)
+			case "nodeRecord":
+				if sess == nil {
+					return errors.New("nodeRecord: invalid session")
+				}
+				return sess.NodeRecord(c, m.Data)
+			case "nodeReplay":
+				if sess == nil {
+					return errors.New("nodeReplay: invalid session")
+				}
+				return sess.NodeReplay(c, m.Data)
 			case "nodeTrain":
 				if sess == nil {
 					return errors.New("nodeTrain: invalid session")
@@ -217,10 +300,13 @@ func (fsm *FlowSessionManager) pathFor(ID string) (string, error) {
 			return "", err
 		}
 	}
-	fpath := filepath.Clean(ID)
-	_, fpath = filepath.Split(fpath)
-	fpath = filepath.Join(storePath, fsm.store, fpath)
-	return fpath, nil
+	return StorePath(fsm.store, ID), nil
+}
+
+// StorePath path of the document ID saved in store
+func StorePath(store, ID string) string {
+	_, fpath := filepath.Split(filepath.Clean(ID))
+	return filepath.Join(storePath, store, fpath)
 }
 
 func e(err error) bool {
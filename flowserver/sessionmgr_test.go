@@ -0,0 +1,102 @@
+package flowserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+type registryMessage struct {
+	OP   string                          `json:"op"`
+	Data map[string]registry.Description `json:"data"`
+}
+
+func TestPushRegistry(t *testing.T) {
+	a := assert.A(t)
+
+	r := registry.New()
+	r.Add("first", func() int { return 1 })
+	fsm := NewFlowSessionManager(r, t.TempDir())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		fsm.CreateSession().ClientAdd(c)
+	}))
+	defer srv.Close()
+
+	c, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	a.Eq(err, nil, "should connect")
+	defer c.Close()
+
+	read := func() registryMessage {
+		c.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			_, raw, err := c.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := registryMessage{}
+			if json.Unmarshal(raw, &msg) == nil && msg.OP == "registry" {
+				return msg
+			}
+		}
+	}
+
+	msg := read()
+	a.Eq(len(msg.Data), 1, "should send the registry on join")
+
+	r.Add("second", func() int { return 2 }).Description("added later")
+	msg = read()
+	a.Eq(len(msg.Data), 2, "should push the added entry")
+	a.Eq(msg.Data["second"].Desc, "added later", "should push described entries")
+
+	r.Add("third", func() int { return 3 })
+	r.Remove("first")
+	msg = read()
+	_, ok := msg.Data["first"]
+	a.Eq(ok, false, "should push removals")
+	a.Eq(len(msg.Data), 2, "changes should be batched in one push")
+}
+
+func TestLimitWriter(t *testing.T) {
+	a := assert.A(t)
+	buf := &bytes.Buffer{}
+	w := &limitWriter{buf, 8}
+	_, err := w.Write([]byte("12345"))
+	a.Eq(err, nil, "should write under the limit")
+	_, err = w.Write([]byte("6789"))
+	a.NotEq(err, nil, "should reject writes past the limit")
+	_, err = w.Write([]byte("678"))
+	a.Eq(err, nil, "should write up to the limit")
+	a.Eq(buf.String(), "12345678", "should not write partial records")
+}
+
+func TestReleaseSession(t *testing.T) {
+	a := assert.A(t)
+	fsm := NewFlowSessionManager(registry.New(), t.TempDir())
+
+	s1, err := fsm.LoadSession("a")
+	a.Eq(err, nil, "should load")
+	s2, _ := fsm.LoadSession("a")
+	a.Eq(s1, s2, "should share the loaded session")
+
+	fsm.ReleaseSession(s1)
+	a.Eq(len(fsm.sessions), 1, "should keep used sessions")
+	fsm.ReleaseSession(s2)
+	a.Eq(len(fsm.sessions), 0, "should drop unused sessions")
+
+	s, _ := fsm.LoadSession("b")
+	a.Eq(s.DocumentUpdate(nil, []byte(`{"nodes": []}`)), nil, "should update")
+	fsm.ReleaseSession(s)
+	a.Eq(len(fsm.sessions), 1, "should keep sessions with unsaved documents")
+}
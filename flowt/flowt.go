@@ -0,0 +1,242 @@
+// Package flowt typed layer over flow, operations carry their result type
+// so graphs built in Go are checked at compile time
+//
+//	f := flow.New()
+//	x := flowt.Input[float64](f, "x")
+//	y := flowt.Op2(f, math.Max, x, flowt.Const(f, 1.0))
+//	res, err := y.Process() // float64
+//
+// Typed operations are regular flow operations, Op returns the untyped
+// operation and Wrap types an existing one
+package flowt
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+
+	"github.com/hexasoftware/flow"
+)
+
+// Operand a typed operation of any type
+type Operand interface {
+	Op() flow.Operation
+	Type() reflect.Type
+}
+
+// Out typed operation with a result of type T
+type Out[T any] struct {
+	op flow.Operation
+}
+
+// Op returns the untyped operation
+func (o Out[T]) Op() flow.Operation { return o.op }
+
+// Type returns the result type
+func (o Out[T]) Type() reflect.Type { return typeOf[T]() }
+
+// Process the operation in a new session with positional inputs
+func (o Out[T]) Process(ginputs ...flow.Data) (T, error) {
+	res, err := o.op.Process(ginputs...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result[T](res)
+}
+
+// Run the operation in session s
+func (o Out[T]) Run(s *flow.Session) (T, error) {
+	res, err := s.Run(o.op)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result[T](res[0])
+}
+
+// Wrap types an untyped operation, the result type is checked when used
+func Wrap[T any](op flow.Operation) Out[T] {
+	return Out[T]{op}
+}
+
+// Const typed const operation
+func Const[T any](f *flow.Flow, v T) Out[T] {
+	return Out[T]{f.Const(v)}
+}
+
+// Var typed var operation
+func Var[T any](f *flow.Flow, name string, initial T) Out[T] {
+	return Out[T]{f.Var(name, initial)}
+}
+
+// SetVar typed setvar operation
+func SetVar[T any](f *flow.Flow, name string, v Out[T]) Out[T] {
+	return Out[T]{f.SetVar(name, v.op)}
+}
+
+// In typed positional input
+func In[T any](f *flow.Flow, i int) Out[T] {
+	return Out[T]{f.In(i)}
+}
+
+// Input typed named input, with an optional default value
+func Input[T any](f *flow.Flow, name string, def ...T) Out[T] {
+	var d flow.Data
+	if len(def) > 0 {
+		d = def[0]
+	}
+	return Out[T]{f.Input(name, typeOf[T](), d)}
+}
+
+// Op0 operation calling fn
+func Op0[R any](f *flow.Flow, fn func() R) Out[R] {
+	return Op0E(f, func() (R, error) { return fn(), nil }, funcName(fn))
+}
+
+// Op1 operation calling fn with a
+func Op1[A, R any](f *flow.Flow, fn func(A) R, a Out[A]) Out[R] {
+	return Op1E(f, func(a A) (R, error) { return fn(a), nil }, a, funcName(fn))
+}
+
+// Op2 operation calling fn with a and b
+func Op2[A, B, R any](f *flow.Flow, fn func(A, B) R, a Out[A], b Out[B]) Out[R] {
+	return Op2E(f, func(a A, b B) (R, error) { return fn(a, b), nil }, a, b, funcName(fn))
+}
+
+// Op3 operation calling fn with a, b and c
+func Op3[A, B, C, R any](f *flow.Flow, fn func(A, B, C) R, a Out[A], b Out[B], c Out[C]) Out[R] {
+	return Op3E(f, func(a A, b B, c C) (R, error) { return fn(a, b, c), nil }, a, b, c, funcName(fn))
+}
+
+// Op0E operation calling fn returning an error, name defaults to the func name
+func Op0E[R any](f *flow.Flow, fn func() (R, error), name ...string) Out[R] {
+	call := func(p ...flow.Data) (flow.Data, error) {
+		return fn()
+	}
+	return Out[R]{f.Func(opName(fn, name), call)}
+}
+
+// Op1E operation calling fn with a returning an error
+func Op1E[A, R any](f *flow.Flow, fn func(A) (R, error), a Out[A], name ...string) Out[R] {
+	call := func(p ...flow.Data) (flow.Data, error) {
+		pa, err := param[A](p, 0)
+		if err != nil {
+			return nil, err
+		}
+		return fn(pa)
+	}
+	return Out[R]{f.Func(opName(fn, name), call, a.op)}
+}
+
+// Op2E operation calling fn with a and b returning an error
+func Op2E[A, B, R any](f *flow.Flow, fn func(A, B) (R, error), a Out[A], b Out[B], name ...string) Out[R] {
+	call := func(p ...flow.Data) (flow.Data, error) {
+		pa, err := param[A](p, 0)
+		if err != nil {
+			return nil, err
+		}
+		pb, err := param[B](p, 1)
+		if err != nil {
+			return nil, err
+		}
+		return fn(pa, pb)
+	}
+	return Out[R]{f.Func(opName(fn, name), call, a.op, b.op)}
+}
+
+// Op3E operation calling fn with a, b and c returning an error
+func Op3E[A, B, C, R any](f *flow.Flow, fn func(A, B, C) (R, error), a Out[A], b Out[B], c Out[C], name ...string) Out[R] {
+	call := func(p ...flow.Data) (flow.Data, error) {
+		pa, err := param[A](p, 0)
+		if err != nil {
+			return nil, err
+		}
+		pb, err := param[B](p, 1)
+		if err != nil {
+			return nil, err
+		}
+		pc, err := param[C](p, 2)
+		if err != nil {
+			return nil, err
+		}
+		return fn(pa, pb, pc)
+	}
+	return Out[R]{f.Func(opName(fn, name), call, a.op, b.op, c.op)}
+}
+
+// Entry typed operation of a flow registry entry, the entry input and output
+// types are checked against params and R when building
+func Entry[R any](f *flow.Flow, name string, params ...Operand) (Out[R], error) {
+	e, err := f.Registry().Entry(name)
+	if err != nil {
+		return Out[R]{f.ErrOp(err)}, err
+	}
+	if len(params) != len(e.Inputs) {
+		err := fmt.Errorf("%s: %v: expected %d params, got %d", name, flow.ErrInput, len(e.Inputs), len(params))
+		return Out[R]{f.ErrOp(err)}, err
+	}
+	ops := make([]flow.Data, len(params))
+	for i, p := range params {
+		if !assignable(p.Type(), e.Inputs[i]) {
+			err := fmt.Errorf("%s: %v: param %d is %v, expected %v", name, flow.ErrInput, i, p.Type(), e.Inputs[i])
+			return Out[R]{f.ErrOp(err)}, err
+		}
+		ops[i] = p.Op()
+	}
+	if rt := typeOf[R](); !assignable(e.Output, rt) {
+		err := fmt.Errorf("%s: %v: returns %v, expected %v", name, flow.ErrOutput, e.Output, rt)
+		return Out[R]{f.ErrOp(err)}, err
+	}
+	return Out[R]{f.Op(name, ops...)}, nil
+}
+
+func assignable(from, to reflect.Type) bool {
+	if from == nil || to == nil {
+		return true
+	}
+	return from.AssignableTo(to)
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// param converts the untyped param i, nil is the zero value
+func param[T any](p []flow.Data, i int) (T, error) {
+	var zero T
+	if i >= len(p) || p[i] == nil {
+		return zero, nil
+	}
+	v, ok := p[i].(T)
+	if !ok {
+		return zero, fmt.Errorf("%v: param %d is %T, expected %v", flow.ErrInput, i, p[i], typeOf[T]())
+	}
+	return v, nil
+}
+
+func result[T any](res flow.Data) (T, error) {
+	var zero T
+	if res == nil {
+		return zero, nil
+	}
+	v, ok := res.(T)
+	if !ok {
+		return zero, fmt.Errorf("%v: result is %T, expected %v", flow.ErrOutput, res, typeOf[T]())
+	}
+	return v, nil
+}
+
+func opName(fn interface{}, name []string) string {
+	if len(name) > 0 {
+		return name[0]
+	}
+	return funcName(fn)
+}
+
+// funcName same automatic naming as the registry
+func funcName(fn interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	return path.Ext(name)[1:]
+}
@@ -0,0 +1,90 @@
+package flowt_test
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/flowt"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func init() {
+	assert.Quiet = true
+}
+
+func TestOps(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	x := flowt.Input[float64](f, "x", 2)
+	y := flowt.Op2(f, math.Max, x, flowt.Const(f, 1.0))
+	s := flowt.Op1(f, strconv.Itoa, flowt.Op1(f, func(v float64) int { return int(v) * 10 }, y))
+
+	res, err := s.Process()
+	a.Eq(err, nil, "should process")
+	a.Eq(res, "20", "should use the input default")
+
+	sess := f.NewSession()
+	sess.NamedInputs(map[string]flow.Data{"x": -3.0})
+	v, err := y.Run(sess)
+	a.Eq(err, nil, "should run")
+	a.Eq(v, 1.0, "should take the max")
+
+	a.Eq(y.Op().Name(), "Max", "should be named after the func")
+}
+
+func TestErrors(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	errBad := errors.New("bad")
+	op := flowt.Op1E(f, func(v int) (int, error) {
+		if v < 0 {
+			return 0, errBad
+		}
+		return v, nil
+	}, flowt.In[int](f, 0), "check")
+
+	v, err := op.Process(1)
+	a.Eq(err, nil, "should not error")
+	a.Eq(v, 1, "should return the input")
+
+	_, err = op.Process(-1)
+	a.Eq(err, errBad, "should return the func error")
+
+	_, err = op.Process("a")
+	a.NotEq(err, nil, "should error with a wrong input type")
+
+	w := flowt.Wrap[string](f.Const(1))
+	_, err = w.Process()
+	a.NotEq(err, nil, "should error with a wrong result type")
+}
+
+func TestVarEntry(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b })
+	f := flow.New().UseRegistry(r)
+
+	c := flowt.Var(f, "c", 1)
+	sum, err := flowt.Entry[int](f, "add", c, flowt.Const(f, 2))
+	a.Eq(err, nil, "should build the entry")
+	inc := flowt.SetVar(f, "c", sum)
+
+	sess := f.NewSession()
+	inc.Run(sess)
+	v, err := flowt.Var(f, "c", 0).Run(sess)
+	a.Eq(err, nil, "should read the var")
+	a.Eq(v, 3, "should have set the var")
+
+	_, err = flowt.Entry[string](f, "add", c, c)
+	a.NotEq(err, nil, "should check the output type")
+	_, err = flowt.Entry[int](f, "add", c, flowt.Const(f, "a"))
+	a.NotEq(err, nil, "should check the param types")
+	_, err = flowt.Entry[int](f, "add", c)
+	a.NotEq(err, nil, "should check the param count")
+}
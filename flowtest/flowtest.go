@@ -0,0 +1,230 @@
+// Package flowtest helpers to test flows and flow-ui documents, registry
+// entries can be replaced by fakes and results compared to golden files
+//
+//	func TestScrape(t *testing.T) {
+//		ft := flowtest.New(t, webops.New())
+//		ft.Fake("httpGet", []byte("<html>...</html>"))
+//		res, err := ft.RunDocument("testdata/scrape.json")
+//		...
+//		ft.Golden("scrape", res)
+//	}
+//
+// Golden files are rewritten with go test -flowtest.update, or -update if
+// the test package defines that flag
+package flowtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/flowserver/flowbuilder"
+	"github.com/hexasoftware/flow/registry"
+)
+
+var update = flag.Bool("flowtest.update", false, "update flowtest golden files")
+
+// updating reports if golden files must be rewritten, test packages often
+// define their own -update flag for their golden files, it is honored too
+func updating() bool {
+	if *update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		v, _ := strconv.ParseBool(f.Value.String())
+		return v
+	}
+	return false
+}
+
+// T flow test helper
+type T struct {
+	testing.TB
+	// Dir where golden files are stored
+	Dir string
+
+	registry *registry.R
+	inputs   []flow.Data
+	named    map[string]flow.Data
+}
+
+// New test helper using a copy of r, the original registry is not modified
+// by fakes
+func New(t testing.TB, r *registry.R) *T {
+	if r == nil {
+		r = registry.New()
+	}
+	return &T{
+		TB:       t,
+		Dir:      "testdata",
+		registry: r.Clone(),
+		named:    map[string]flow.Data{},
+	}
+}
+
+// Fake replaces the func of entry name with fn, entries not registered are
+// added, if fn is not a func the entry returns fn as a canned value
+func (ft *T) Fake(name string, fn interface{}) *T {
+	ft.Helper()
+	if fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		canned, err := ft.cannedFunc(name, fn)
+		if err != nil {
+			ft.Fatalf("flowtest: fake %s: %v", name, err)
+			return ft
+		}
+		fn = canned
+	}
+	// replace keeps the name and namespace of registered entries
+	d := ft.registry.Replace(name, fn)
+	if errors.Is(d.Err, registry.ErrNotFound) {
+		d = ft.registry.Add(name, fn)
+	}
+	if d.Err != nil {
+		ft.Fatalf("flowtest: fake %s: %v", name, d.Err)
+	}
+	return ft
+}
+
+// cannedFunc func with the signature of entry name returning v
+func (ft *T) cannedFunc(name string, v interface{}) (interface{}, error) {
+	orig, err := ft.registry.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	typ := reflect.TypeOf(orig)
+	if typ.NumOut() == 0 {
+		return nil, fmt.Errorf("entry has no output")
+	}
+	ret := make([]reflect.Value, typ.NumOut())
+	for i := range ret {
+		ret[i] = reflect.Zero(typ.Out(i))
+	}
+	if v != nil {
+		val := reflect.ValueOf(v)
+		if !val.Type().AssignableTo(typ.Out(0)) {
+			return nil, fmt.Errorf("%T is not assignable to %v", v, typ.Out(0))
+		}
+		ret[0] = reflect.New(typ.Out(0)).Elem()
+		ret[0].Set(val)
+	}
+	return reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+		return ret
+	}).Interface(), nil
+}
+
+// Registry returns the registry with the fakes
+func (ft *T) Registry() *registry.R {
+	return ft.registry
+}
+
+// Flow creates a flow using the registry with the fakes, operations must
+// be built after the fakes are set
+func (ft *T) Flow() *flow.Flow {
+	return flow.New().UseRegistry(ft.registry)
+}
+
+// Inputs sets positional inputs for the next runs
+func (ft *T) Inputs(ginputs ...flow.Data) *T {
+	ft.inputs = ginputs
+	return ft
+}
+
+// NamedInputs sets named inputs for the next runs, numeric names are
+// positional inputs same as in documents
+func (ft *T) NamedInputs(inputs map[string]flow.Data) *T {
+	for k, v := range inputs {
+		i, err := strconv.Atoi(k)
+		if err != nil {
+			ft.named[k] = v
+			continue
+		}
+		for len(ft.inputs) <= i {
+			ft.inputs = append(ft.inputs, nil)
+		}
+		ft.inputs[i] = v
+	}
+	return ft
+}
+
+// Run ops of f in a new session with the inputs
+func (ft *T) Run(f *flow.Flow, ops ...flow.Operation) ([]flow.Data, error) {
+	sess := f.NewSession()
+	sess.Inputs(ft.inputs...)
+	sess.NamedInputs(ft.named)
+	return sess.Run(ops...)
+}
+
+// RunDocument loads the flow-ui document file and runs the nodes IDs,
+// nodes without outgoing links are run if no IDs are given, results are
+// keyed by node ID
+func (ft *T) RunDocument(file string, IDs ...string) (map[string]flow.Data, error) {
+	rawDoc, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	builder := flowbuilder.New(ft.registry).Load(rawDoc)
+	if builder.Err != nil {
+		return nil, builder.Err
+	}
+	if len(IDs) == 0 {
+		IDs = builder.Doc.Sinks()
+	}
+	ops := make([]flow.Operation, len(IDs))
+	for i, ID := range IDs {
+		if builder.Doc.FetchNodeByID(ID) == nil {
+			return nil, fmt.Errorf("node not found [%v]", ID)
+		}
+		ops[i] = builder.Build(ID)
+	}
+	if builder.Err != nil {
+		return nil, builder.Err
+	}
+	res, err := ft.Run(builder.Flow(), ops...)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]flow.Data{}
+	for i, ID := range IDs {
+		ret[ID] = res[i]
+	}
+	return ret, nil
+}
+
+// Golden compares v encoded as JSON with the golden file name in Dir,
+// the file is written instead if the -flowtest.update flag is set
+func (ft *T) Golden(name string, v interface{}) {
+	ft.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		ft.Fatalf("flowtest: golden %s: %v", name, err)
+		return
+	}
+	got = append(got, '\n')
+	fpath := filepath.Join(ft.Dir, name+".golden")
+	if updating() {
+		if err := os.MkdirAll(ft.Dir, 0755); err != nil {
+			ft.Fatalf("flowtest: golden %s: %v", name, err)
+		}
+		if err := ioutil.WriteFile(fpath, got, 0644); err != nil {
+			ft.Fatalf("flowtest: golden %s: %v", name, err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		ft.Fatalf("flowtest: golden %s: %v, run with -flowtest.update to create it", name, err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		ft.Errorf("flowtest: golden %s mismatch\n--- got\n%s--- want\n%s", name, got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package flowtest_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hexasoftware/flow/flowtest"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+// test packages defining their own -update flag must not conflict
+var _ = flag.Bool("update", false, "update golden files")
+
+func init() {
+	assert.Quiet = true
+}
+
+func TestRunDocument(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("httpGet", func(url string) (string, error) {
+		return "", errors.New("no network in tests")
+	})
+	r.Add("length", func(s string) int { return len(s) })
+	r.Add("upper", strings.ToUpper)
+
+	ft := flowtest.New(t, r)
+	_, err := ft.RunDocument("testdata/fetch.json")
+	a.NotEq(err, nil, "should use the real entry")
+
+	ft.Fake("httpGet", "hello")
+	ft.NamedInputs(map[string]interface{}{"url": "http://example.com"})
+	res, err := ft.RunDocument("testdata/fetch.json")
+	a.Eq(err, nil, "should run with the fake")
+	a.Eq(res["len"], 5, "should use the canned value")
+	ft.Golden("fetch", res)
+
+	_, err = r.Entry("httpGet")
+	a.Eq(err, nil, "original registry should keep the entry")
+	_, err = flowtest.New(t, r).RunDocument("testdata/fetch.json")
+	a.NotEq(err, nil, "original registry should not be faked")
+}
+
+func TestFlow(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("upper", strings.ToUpper)
+	ft := flowtest.New(t, r)
+	var called string
+	ft.Fake("httpGet", func(url string) (string, error) {
+		called = url
+		return "abc", nil
+	})
+	f := ft.Flow()
+	op := f.Op("upper", f.Op("httpGet", f.In(0)))
+	res, err := ft.Inputs("u").Run(f, op)
+	a.Eq(err, nil, "should run")
+	a.Eq(res[0], "ABC", "should use the fake func")
+	a.Eq(called, "u", "should pass the input")
+}
+
+type recorder struct {
+	testing.TB
+	failed string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	a := assert.A(t)
+	rec := &recorder{TB: t}
+	ft := flowtest.New(rec, nil)
+	ft.Golden("fetch", map[string]interface{}{"len": 6})
+	a.Eq(strings.HasPrefix(rec.failed, "flowtest: golden fetch mismatch"), true, "should report the mismatch")
+
+	rec.failed = ""
+	ft.Fake("missing", 1)
+	a.NotEq(rec.failed, "", "should fail to fake a missing entry with a value")
+}
+
+func TestFakeNamespaced(t *testing.T) {
+	a := assert.A(t)
+	web := registry.New().UseNamespace("web")
+	web.Add("httpGet", func(url string) (string, error) {
+		return "", errors.New("no network in tests")
+	})
+	str := registry.New().UseNamespace("strings")
+	str.Add("length", func(s string) int { return len(s) })
+	str.Add("upper", strings.ToUpper)
+	// merged into a namespaced registry, adding httpGet would namespace it
+	// as strings.httpGet
+	r := str
+	r.MergeConflict(web, registry.ConflictError)
+
+	ft := flowtest.New(t, r)
+	ft.Fake("httpGet", "hello")
+	ft.NamedInputs(map[string]interface{}{"url": "http://example.com"})
+	res, err := ft.RunDocument("testdata/fetch.json")
+	a.Eq(err, nil, "should run with the fake of a namespaced entry")
+	a.Eq(res["len"], 5, "should use the canned value")
+}
@@ -1,6 +1,6 @@
 module github.com/hexasoftware/flow
 
-go 1.16
+go 1.22
 
 require (
 	github.com/gohxs/prettylog v0.0.0-20190304114953-faf23b5b615b
@@ -17,11 +17,16 @@ type Hook struct {
 	Start  func(op Operation, triggerTime time.Time)
 	Finish func(op Operation, triggerTime time.Time, res interface{})
 	Error  func(op Operation, triggerTime time.Time, err error)
-	Any    func(name string, op Operation, triggerTime time.Time, extra ...interface{})
+	// Progress of a RunBatch, done and failed input sets out of total
+	Progress func(done, failed, total int, triggerTime time.Time)
+	Any      func(name string, op Operation, triggerTime time.Time, extra ...interface{})
 }
 
-// Trigger a hook
+// Trigger a hook, nil hooks trigger nothing
 func (hs *Hooks) Trigger(name string, op Operation, extra ...Data) {
+	if hs == nil {
+		return
+	}
 	hs.Lock()
 	defer hs.Unlock()
 
@@ -46,6 +51,10 @@ func (hs *Hooks) Trigger(name string, op Operation, extra ...Data) {
 			if h.Error != nil {
 				h.Error(op, time.Now(), extra[0].(error))
 			}
+		case "Progress":
+			if h.Progress != nil {
+				h.Progress(extra[0].(int), extra[1].(int), extra[2].(int), time.Now())
+			}
 		}
 	}
 }
@@ -54,6 +63,9 @@ func (hs *Hooks) wait(op Operation)             { hs.Trigger("Wait", op) }
 func (hs *Hooks) start(op Operation)            { hs.Trigger("Start", op) }
 func (hs *Hooks) finish(op Operation, res Data) { hs.Trigger("Finish", op, res) }
 func (hs *Hooks) error(op Operation, err error) { hs.Trigger("Error", op, err) }
+func (hs *Hooks) progress(done, failed, total int) {
+	hs.Trigger("Progress", nil, done, failed, total)
+}
 
 // Attach attach a hook
 func (hs *Hooks) Attach(h Hook) {
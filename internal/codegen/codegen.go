@@ -0,0 +1,134 @@
+// Package codegen helpers shared by the flow code generators
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RegistryPath import path of the registry package
+const RegistryPath = "github.com/hexasoftware/flow/registry"
+
+// Package a parsed and type checked package
+type Package struct {
+	Fset  *token.FileSet
+	Files []*ast.File
+	Info  *types.Info
+	Types *types.Package
+}
+
+// Load parses and type checks the package in dir, test files and the
+// generated file outName are skipped. Type errors accepted by ignore are
+// not returned, generators use it for references to the code they generate
+func Load(dir, outName string, mode parser.Mode, ignore func(types.Error) bool) (*Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != outName
+	}, mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package, found %d", len(pkgs))
+	}
+	p := &Package{
+		Fset: fset,
+		Info: &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+		},
+	}
+	var name string
+	for n, pkg := range pkgs {
+		name = n
+		for _, f := range pkg.Files {
+			p.Files = append(p.Files, f)
+		}
+	}
+
+	var typeErr error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			if te, ok := err.(types.Error); ok && ignore != nil && ignore(te) {
+				return
+			}
+			if typeErr == nil {
+				typeErr = err
+			}
+		},
+	}
+	p.Types, _ = conf.Check(name, fset, p.Files, p.Info)
+	if typeErr != nil {
+		return nil, typeErr
+	}
+	return p, nil
+}
+
+// RegistryAdds calls fn with every registry.Add or (*registry.R).Add call
+// of the package
+func (p *Package) RegistryAdds(fn func(f *ast.File, call *ast.CallExpr)) {
+	for _, f := range p.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if ok && IsRegistryAdd(p.Info, call.Fun) {
+				fn(f, call)
+			}
+			return true
+		})
+	}
+}
+
+// IsRegistryAdd checks if fun is registry.Add or (*registry.R).Add
+func IsRegistryAdd(info *types.Info, fun ast.Expr) bool {
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Add" {
+		return false
+	}
+	if s, ok := info.Selections[sel]; ok {
+		return s.Kind() == types.MethodVal && types.TypeString(s.Recv(), nil) == "*"+RegistryPath+".R"
+	}
+	obj := info.Uses[sel.Sel]
+	return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == RegistryPath
+}
+
+// WriteImports writes the import block of imports, path to package name,
+// standard library packages first
+func WriteImports(w io.Writer, imports map[string]string) {
+	paths := []string{}
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := IsStd(paths[i]), IsStd(paths[j])
+		if si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	fmt.Fprintf(w, "import (\n")
+	for i, p := range paths {
+		if i > 0 && IsStd(p) != IsStd(paths[i-1]) {
+			fmt.Fprintf(w, "\n")
+		}
+		if name := imports[p]; name != p[strings.LastIndex(p, "/")+1:] {
+			fmt.Fprintf(w, "%s ", name)
+		}
+		fmt.Fprintf(w, "%q\n", p)
+	}
+	fmt.Fprintf(w, ")\n")
+}
+
+// IsStd checks if pkg is a standard library import path
+func IsStd(pkg string) bool {
+	return !strings.Contains(strings.Split(pkg, "/")[0], ".")
+}
@@ -0,0 +1,80 @@
+package flow
+
+// WalkFunc is called for each operation visited by Walk
+type WalkFunc func(op Operation) error
+
+// ID of the operation, assigned in creation order and unique within the flow
+func (o *operation) ID() int { return o.id }
+
+// Kind of the operation: func, var, setvar, const, in, input, error or
+// alias for operations merged by Optimize into their only input
+func (o *operation) Kind() string { return o.kind }
+
+// Name of the registry entry for func operations, the var or input name,
+// the input index for positional inputs
+func (o *operation) Name() string { return o.name }
+
+// Inputs operations used as inputs of this operation
+func (o *operation) Inputs() []Operation {
+	ret := make([]Operation, len(o.inputs))
+	for i, in := range o.inputs {
+		ret[i] = in
+	}
+	return ret
+}
+
+// Source file and line where the operation was created
+func (o *operation) Source() (string, int) { return o.file, o.line }
+
+// Operations returns every operation of the flow in creation order
+func (f *Flow) Operations() []Operation {
+	ret := make([]Operation, len(f.operations))
+	for i, op := range f.operations {
+		ret[i] = op
+	}
+	return ret
+}
+
+// Operation fetches an operation by ID
+func (f *Flow) Operation(ID int) (Operation, error) {
+	for _, op := range f.operations {
+		if op.id == ID {
+			return op, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Walk visits the operations reachable from ops once each, inputs are
+// visited before the operations using them, an error from fn stops the walk
+func Walk(fn WalkFunc, ops ...Operation) error {
+	roots := make([]*operation, len(ops))
+	for i, op := range ops {
+		roots[i] = op.(*operation)
+	}
+	return walk(roots, func(op *operation) error { return fn(op) })
+}
+
+// walk depth first calling fn in topological order
+func walk(roots []*operation, fn func(op *operation) error) error {
+	visited := map[*operation]bool{}
+	var visit func(op *operation) error
+	visit = func(op *operation) error {
+		if visited[op] {
+			return nil
+		}
+		visited[op] = true
+		for _, in := range op.inputs {
+			if err := visit(in); err != nil {
+				return err
+			}
+		}
+		return fn(op)
+	}
+	for _, op := range roots {
+		if err := visit(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package flow_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+)
+
+func TestIntrospect(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	in := f.In(0)
+	v := f.Var("v", 1)
+	add := f.Op("add", in, v)
+
+	a.Eq(len(f.Operations()), 4, "should list every operation")
+	a.Eq(add.Kind(), "func", "should be a func")
+	a.Eq(add.Name(), "add", "should have the entry name")
+	a.Eq(v.Name(), "v", "var should have the var name")
+	a.Eq(in.Name(), "0", "positional input should have the input index")
+	a.Eq(add.Inputs(), []flow.Operation{in, v}, "should have inputs")
+	a.Eq(in.ID() < add.ID(), true, "ids should follow creation order")
+
+	file, line := add.Source()
+	a.Eq(filepath.Base(file), "introspect_test.go", "should point to the creation file")
+	a.NotEq(line, 0, "should have a line")
+
+	op, err := f.Operation(add.ID())
+	a.Eq(err, nil, "should find by id")
+	a.Eq(op, add, "should be the same operation")
+}
+
+func TestWalk(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	in := f.In(0)
+	mul := f.Op("vecmul", in, in)
+	add := f.Op("vecadd", mul, in)
+
+	visited := []flow.Operation{}
+	err := flow.Walk(func(op flow.Operation) error {
+		visited = append(visited, op)
+		return nil
+	}, add)
+	a.Eq(err, nil, "should not error")
+	a.Eq(visited, []flow.Operation{in, mul, add}, "should visit inputs first and once")
+
+	stop := errors.New("stop")
+	err = flow.Walk(func(op flow.Operation) error { return stop }, add)
+	a.Eq(err, stop, "should stop on error")
+}
@@ -0,0 +1,272 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// flowJSON serialized form of a flow, operations are listed after their
+// inputs
+type flowJSON struct {
+	Operations []opJSON     `json:"operations"`
+	Outputs    []outputJSON `json:"outputs,omitempty"`
+}
+
+type opJSON struct {
+	ID     int             `json:"id"`
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name,omitempty"`
+	Inputs []int           `json:"inputs,omitempty"`
+	Type   string          `json:"type,omitempty"`  // const or named input type
+	Value  json.RawMessage `json:"value,omitempty"` // const value or named input default
+}
+
+type outputJSON struct {
+	Name string `json:"name"`
+	ID   int    `json:"id"`
+}
+
+// MarshalJSON serializes the operations, named inputs and named outputs
+// of the flow. Func operations are stored by their entry name, operations
+// that can't be rebuilt from the registry (factories, Func operations not
+// named after an entry and failed operations) are rejected, so are values
+// that don't survive json encoding. Aliases left by Optimize are stored as
+// the operation they run
+func (f *Flow) MarshalJSON() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	resolve := func(op *operation) *operation {
+		for op.kind == "alias" {
+			op = op.inputs[0]
+		}
+		return op
+	}
+	roots := append([]*operation{}, f.inputs...)
+	roots = append(roots, f.operations...)
+	for _, out := range f.outputs {
+		roots = append(roots, out.op)
+	}
+
+	fj := flowJSON{Operations: []opJSON{}}
+	err := walk(roots, func(op *operation) error {
+		if op.kind == "alias" {
+			return nil
+		}
+		oj := opJSON{ID: op.id, Kind: op.kind, Name: op.name}
+		for _, in := range op.inputs {
+			oj.Inputs = append(oj.Inputs, resolve(in).id)
+		}
+		var err error
+		switch op.kind {
+		case "func":
+			e, eerr := f.registry.Entry(op.name)
+			if eerr != nil || e.Factory() {
+				return fmt.Errorf("%v: %v is not a registry entry and can't be serialized", ErrOperation, op)
+			}
+		case "const":
+			v, _ := op.executor(nil)
+			if v != nil {
+				oj.Type = reflect.TypeOf(v).String()
+			}
+			oj.Value, err = encodeValue(v)
+		case "input":
+			if op.param.Type != nil {
+				oj.Type = op.param.Type.String()
+			}
+			oj.Value, err = encodeValue(op.param.Default)
+		case "in", "var", "setvar":
+		default:
+			return fmt.Errorf("%v: %v can't be serialized", ErrOperation, op)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %v", op, err)
+		}
+		fj.Operations = append(fj.Operations, oj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, out := range f.outputs {
+		fj.Outputs = append(fj.Outputs, outputJSON{out.Name, resolve(out.op).id})
+	}
+	return json.Marshal(fj)
+}
+
+// UnmarshalJSON adds the operations of a serialized flow to f, entries are
+// resolved from the flow registry, it must be set before unmarshaling.
+// Values are decoded as the type the consuming entry expects or as the
+// builtin type they were serialized from. Nothing is added on error
+func (f *Flow) UnmarshalJSON(data []byte) error {
+	fj := flowJSON{}
+	if err := json.Unmarshal(data, &fj); err != nil {
+		return err
+	}
+	n, ni := len(f.operations), len(f.inputs)
+	if err := f.load(fj); err != nil {
+		// drop the operations built before the error
+		f.operations = f.operations[:n]
+		f.inputs = f.inputs[:ni]
+		return err
+	}
+	return nil
+}
+
+func (f *Flow) load(fj flowJSON) error {
+	ops := map[int]*operation{}
+	for _, oj := range fj.Operations {
+		inputs := make([]Data, len(oj.Inputs))
+		for i, id := range oj.Inputs {
+			in, ok := ops[id]
+			if !ok {
+				return fmt.Errorf("%v: operation %d uses %d before it is defined", ErrInput, oj.ID, id)
+			}
+			inputs[i] = in
+		}
+
+		var op Operation
+		switch oj.Kind {
+		case "func":
+			e, err := f.registry.Entry(oj.Name)
+			if err != nil {
+				return fmt.Errorf("%v '%s'", err, oj.Name)
+			}
+			if e.Factory() {
+				return fmt.Errorf("%v: '%s' is a factory", ErrOperation, oj.Name)
+			}
+			op = f.Op(oj.Name, inputs...)
+		case "const":
+			typ, err := fj.valueType(f.registry, oj)
+			if err != nil {
+				return err
+			}
+			v, err := decodeValue(oj.Value, typ)
+			if err != nil {
+				return fmt.Errorf("%v: const %d: %v", ErrInput, oj.ID, err)
+			}
+			op = f.Const(v)
+		case "in":
+			i, err := strconv.Atoi(oj.Name)
+			if err != nil {
+				return fmt.Errorf("%v: in %d: %v", ErrInput, oj.ID, err)
+			}
+			op = f.In(i)
+		case "input":
+			typ, err := fj.valueType(f.registry, oj)
+			if err != nil {
+				return err
+			}
+			def, err := decodeValue(oj.Value, typ)
+			if err != nil {
+				return fmt.Errorf("%v: '%s' default: %v", ErrInput, oj.Name, err)
+			}
+			op = f.Input(oj.Name, typ, def)
+		case "var", "setvar":
+			if len(inputs) != 1 {
+				return fmt.Errorf("%v: %s '%s' needs one input", ErrInput, oj.Kind, oj.Name)
+			}
+			if oj.Kind == "var" {
+				op = f.Var(oj.Name, inputs[0])
+			} else {
+				op = f.SetVar(oj.Name, inputs[0])
+			}
+		default:
+			return fmt.Errorf("%v: unknown kind '%s'", ErrOperation, oj.Kind)
+		}
+		o := op.(*operation)
+		if o.kind == "error" {
+			_, err := o.executor(nil)
+			return err
+		}
+		ops[oj.ID] = o
+	}
+	for _, out := range fj.Outputs {
+		op, ok := ops[out.ID]
+		if !ok {
+			return fmt.Errorf("%v: '%s' uses undefined operation %d", ErrOutput, out.Name, out.ID)
+		}
+		f.Output(out.Name, op)
+	}
+	return nil
+}
+
+// valueType resolves the type serialized for the value of oj, from the
+// inputs of the entries using it or from the builtin types
+func (fj flowJSON) valueType(r *registry.R, oj opJSON) (reflect.Type, error) {
+	if oj.Type == "" {
+		return nil, nil
+	}
+	for _, user := range fj.Operations {
+		if user.Kind != "func" {
+			continue
+		}
+		e, err := r.Entry(user.Name)
+		if err != nil {
+			continue
+		}
+		for i, id := range user.Inputs {
+			if id == oj.ID && i < len(e.Inputs) && e.Inputs[i].String() == oj.Type {
+				return e.Inputs[i], nil
+			}
+		}
+	}
+	if typ := builtinType(oj.Type); typ != nil {
+		return typ, nil
+	}
+	return nil, fmt.Errorf("%v: unknown type %s of operation %d", ErrInput, oj.Type, oj.ID)
+}
+
+var builtinTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		false, "", 0, int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), new(interface{}),
+	} {
+		typ := reflect.TypeOf(v)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		builtinTypes[typ.String()] = typ
+	}
+}
+
+// builtinType resolves basic types and slices and string keyed maps of them
+func builtinType(name string) reflect.Type {
+	switch {
+	case strings.HasPrefix(name, "[]"):
+		if elem := builtinType(name[2:]); elem != nil {
+			return reflect.SliceOf(elem)
+		}
+	case strings.HasPrefix(name, "map[string]"):
+		if elem := builtinType(name[len("map[string]"):]); elem != nil {
+			return reflect.MapOf(reflect.TypeOf(""), elem)
+		}
+	}
+	return builtinTypes[name]
+}
+
+// decodeValue decodes raw as typ or as generic json if typ is nil, a
+// missing value is nil
+func decodeValue(raw json.RawMessage, typ reflect.Type) (Data, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if typ == nil {
+		var v Data
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+	v := reflect.New(typ)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
@@ -0,0 +1,98 @@
+package flow_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+type celsius float64
+
+func TestMarshal(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b }).Pure()
+	r.Add("warm", func(c celsius) bool { return c > 20 })
+	r.Add("sum", func(v []float64) float64 {
+		s := 0.0
+		for _, n := range v {
+			s += n
+		}
+		return s
+	})
+
+	f := flow.New().UseRegistry(r)
+	x := f.Input("x", reflect.TypeOf(0), 10)
+	total := f.Op("add", x, f.In(0))
+	f.Output("total", f.Op("add", total, 1))
+	f.Output("dup", f.Op("add", total, 1))
+	f.Output("warm", f.Op("warm", celsius(25)))
+	f.Output("sum", f.Op("sum", f.Var("v", []float64{1, 2})))
+	f.Optimize()
+
+	data, err := json.Marshal(f)
+	a.Eq(err, nil, "should marshal")
+	t.Log(string(data))
+
+	g := flow.New().UseRegistry(r)
+	a.Eq(json.Unmarshal(data, g), nil, "should unmarshal")
+	a.Eq(g.Signature(), f.Signature(), "should keep the signature")
+
+	s := g.NewSession()
+	s.Inputs(2)
+	res, err := s.RunNamed(map[string]flow.Data{})
+	a.Eq(err, nil, "should run")
+	a.Eq(res, map[string]flow.Data{"total": 13, "dup": 13, "warm": true, "sum": 3.0}, "should compute the same results")
+
+	data, err = json.Marshal(g)
+	a.Eq(err, nil, "should marshal the loaded flow")
+	h := flow.New().UseRegistry(r)
+	a.Eq(json.Unmarshal(data, h), nil, "should unmarshal the loaded flow")
+	again, _ := json.Marshal(h)
+	a.Eq(string(again), string(data), "loaded flows should marshal the same")
+}
+
+func TestMarshalErrors(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("neg", func(a int) int { return -a })
+	r.Add("scale", func(factor float64) func(float64) float64 {
+		return func(v float64) float64 { return v * factor }
+	})
+
+	f := flow.New().UseRegistry(r)
+	f.Func("double", func(a int) int { return a * 2 }, 1)
+	_, err := json.Marshal(f)
+	a.NotEq(err, nil, "should reject Func operations")
+
+	f = flow.New().UseRegistry(r)
+	f.Factory("scale", []flow.Data{3.0}, 1.0)
+	_, err = json.Marshal(f)
+	a.NotEq(err, nil, "should reject factory operations")
+
+	f = flow.New().UseRegistry(r)
+	f.Op("neg", func() {})
+	_, err = json.Marshal(f)
+	a.NotEq(err, nil, "should reject values that can't be encoded")
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown entry", `{"operations": [{"id": 1, "kind": "func", "name": "missing"}]}`},
+		{"undefined input", `{"operations": [{"id": 1, "kind": "func", "name": "neg", "inputs": [2]}]}`},
+		{"unknown type", `{"operations": [{"id": 1, "kind": "const", "type": "foo.Bar", "value": 1}]}`},
+		{"wrong value", `{"operations": [{"id": 1, "kind": "const", "type": "int", "value": "1"}]}`},
+		{"undefined output", `{"operations": [], "outputs": [{"name": "x", "id": 1}]}`},
+	}
+	for _, tt := range tests {
+		g := flow.New().UseRegistry(r)
+		err := json.Unmarshal([]byte(tt.data), g)
+		a.NotEq(err, nil, tt.name+": should fail")
+		a.Eq(len(g.Operations()), 0, tt.name+": should not add operations")
+	}
+}
@@ -0,0 +1,51 @@
+package flow
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hexasoftware/flow/metrics"
+)
+
+// Operation metrics labelled by entry and tag, exposed by metrics.Handler()
+var (
+	opExecutions = metrics.Default.NewCounter("flow_operation_executions_total",
+		"Number of operation executions", "entry", "tag")
+	opErrors = metrics.Default.NewCounter("flow_operation_errors_total",
+		"Number of operation executions that failed", "entry", "tag")
+	opCacheHits = metrics.Default.NewCounter("flow_operation_cache_hits_total",
+		"Number of operation results loaded from session cache", "entry", "tag")
+	opDuration = metrics.Default.NewHistogram("flow_operation_duration_seconds",
+		"Operation execution time including waiting for inputs", nil, "entry", "tag")
+	opExecDuration = metrics.Default.NewHistogram("flow_operation_exec_seconds",
+		"Operation execution time once its inputs are resolved", nil, "entry", "tag")
+)
+
+// setLabels resolves the metric labels, called when the operation kind,
+// name or entry change
+func (o *operation) setLabels() {
+	name := o.name
+	if o.kind != "func" {
+		name = o.kind
+	}
+	o.labels = []string{name, strings.Join(o.tags(), ",")}
+}
+
+func (o *operation) metricLabels() []string {
+	return o.labels
+}
+
+// observeRun records an execution started at start with inputs resolved
+// at ready, zero if the operation has no inputs to wait for
+func observeRun(op *operation, start, ready time.Time, err error) {
+	labels := op.metricLabels()
+	opExecutions.Inc(labels...)
+	opDuration.Observe(time.Since(start).Seconds(), labels...)
+	if ready.IsZero() {
+		ready = start
+	}
+	opExecDuration.Observe(time.Since(ready).Seconds(), labels...)
+	if err != nil {
+		opErrors.Inc(labels...)
+	}
+}
@@ -0,0 +1,242 @@
+// Package metrics minimal prometheus style metrics in text exposition format
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default registry used by flow and flowserver
+var Default = New()
+
+// DefBuckets default histogram buckets in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(w *bytes.Buffer)
+}
+
+// Registry holds a set of metrics
+type Registry struct {
+	sync.Mutex
+	metrics []collector
+}
+
+// New creates a new metrics registry
+func New() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.Lock()
+	defer r.Unlock()
+	r.metrics = append(r.metrics, c)
+}
+
+// ServeHTTP writes every metric in text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(r.Bytes())
+}
+
+// Bytes returns every metric in text exposition format
+func (r *Registry) Bytes() []byte {
+	r.Lock()
+	defer r.Unlock()
+	buf := bytes.NewBuffer(nil)
+	for _, m := range r.metrics {
+		m.write(buf)
+	}
+	return buf.Bytes()
+}
+
+// Handler returns the http handler for the Default registry
+func Handler() http.Handler {
+	return Default
+}
+
+// vec common labeled values storage
+type vec struct {
+	sync.Mutex
+	name   string
+	help   string
+	typ    string
+	labels []string
+	values map[string]interface{}
+}
+
+func newVec(name, help, typ string, labels []string) vec {
+	return vec{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		values: map[string]interface{}{},
+	}
+}
+
+// labelEscaper escapes label values as defined by the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// key builds the label pairs for the values
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d labels got %d", v.name, len(v.labels), len(values)))
+	}
+	pairs := make([]string, len(values))
+	for i, l := range v.labels {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, l, labelEscaper.Replace(values[i]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v *vec) header(w *bytes.Buffer) []string {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ)
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter a monotonic labeled counter
+type Counter struct{ vec }
+
+// NewCounter creates and registers a counter in r
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{newVec(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter with label values
+func (c *Counter) Inc(labels ...string) { c.Add(1, labels...) }
+
+// Add adds n to the counter with label values
+func (c *Counter) Add(n float64, labels ...string) {
+	c.Lock()
+	defer c.Unlock()
+	k := c.key(labels)
+	cur, _ := c.values[k].(float64)
+	c.values[k] = cur + n
+}
+
+func (c *Counter) write(w *bytes.Buffer) {
+	c.Lock()
+	defer c.Unlock()
+	for _, k := range c.header(w) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, braces(k), formatFloat(c.values[k].(float64)))
+	}
+}
+
+// Gauge a labeled value that can go up and down
+type Gauge struct{ Counter }
+
+// NewGauge creates and registers a gauge in r
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{Counter{newVec(name, help, "gauge", labels)}}
+	r.register(g)
+	return g
+}
+
+// Dec decrements the gauge with label values
+func (g *Gauge) Dec(labels ...string) { g.Add(-1, labels...) }
+
+// Set sets the gauge value with label values
+func (g *Gauge) Set(n float64, labels ...string) {
+	g.Lock()
+	defer g.Unlock()
+	g.values[g.key(labels)] = n
+}
+
+// Histogram labeled observations in buckets
+type Histogram struct {
+	vec
+	buckets []float64
+}
+
+type histValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram creates and registers a histogram in r
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	h := &Histogram{newVec(name, help, "histogram", labels), buckets}
+	r.register(h)
+	return h
+}
+
+// Observe adds an observation with label values
+func (h *Histogram) Observe(v float64, labels ...string) {
+	h.Lock()
+	defer h.Unlock()
+	k := h.key(labels)
+	hv, ok := h.values[k].(*histValue)
+	if !ok {
+		hv = &histValue{counts: make([]uint64, len(h.buckets))}
+		h.values[k] = hv
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			hv.counts[i]++
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// Stats returns the number and sum of observations with label values
+func (h *Histogram) Stats(labels ...string) (count uint64, sum float64) {
+	h.Lock()
+	defer h.Unlock()
+	hv, ok := h.values[h.key(labels)].(*histValue)
+	if !ok {
+		return 0, 0
+	}
+	return hv.count, hv.sum
+}
+
+func (h *Histogram) write(w *bytes.Buffer) {
+	h.Lock()
+	defer h.Unlock()
+	for _, k := range h.header(w) {
+		hv := h.values[k].(*histValue)
+		sep := ""
+		if k != "" {
+			sep = ","
+		}
+		for i, b := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", h.name, k, sep, formatFloat(b), hv.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", h.name, k, sep, hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, braces(k), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, braces(k), hv.count)
+	}
+}
+
+func braces(k string) string {
+	if k == "" {
+		return ""
+	}
+	return "{" + k + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/metrics"
+)
+
+func TestCounter(t *testing.T) {
+	a := assert.A(t)
+	r := metrics.New()
+	c := r.NewCounter("test_total", "test counter", "entry")
+	c.Inc("a")
+	c.Add(2, "a")
+	c.Inc("b")
+
+	out := string(r.Bytes())
+	a.Eq(strings.Contains(out, "# TYPE test_total counter"), true, "should declare type")
+	a.Eq(strings.Contains(out, `test_total{entry="a"} 3`), true, "should sum values")
+	a.Eq(strings.Contains(out, `test_total{entry="b"} 1`), true, "should keep labels apart")
+}
+
+func TestGauge(t *testing.T) {
+	a := assert.A(t)
+	r := metrics.New()
+	g := r.NewGauge("test_gauge", "test gauge")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+
+	out := string(r.Bytes())
+	a.Eq(strings.Contains(out, "test_gauge 1\n"), true, "gauge should be 1")
+	g.Set(10)
+	a.Eq(strings.Contains(string(r.Bytes()), "test_gauge 10\n"), true, "gauge should be set")
+}
+
+func TestHistogram(t *testing.T) {
+	a := assert.A(t)
+	r := metrics.New()
+	h := r.NewHistogram("test_seconds", "test histogram", []float64{1, 2}, "entry")
+	h.Observe(0.5, "a")
+	h.Observe(1.5, "a")
+	h.Observe(3, "a")
+
+	out := string(r.Bytes())
+	a.Eq(strings.Contains(out, `test_seconds_bucket{entry="a",le="1"} 1`), true, "first bucket")
+	a.Eq(strings.Contains(out, `test_seconds_bucket{entry="a",le="2"} 2`), true, "second bucket")
+	a.Eq(strings.Contains(out, `test_seconds_bucket{entry="a",le="+Inf"} 3`), true, "inf bucket")
+	a.Eq(strings.Contains(out, `test_seconds_count{entry="a"} 3`), true, "count")
+}
+
+func TestHistogramStats(t *testing.T) {
+	a := assert.A(t)
+	r := metrics.New()
+	h := r.NewHistogram("test_seconds", "test histogram", nil, "entry")
+	h.Observe(1, "a")
+	h.Observe(2, "a")
+
+	count, sum := h.Stats("a")
+	a.Eq(count, uint64(2), "should count observations")
+	a.Eq(sum, 3.0, "should sum observations")
+
+	count, _ = h.Stats("b")
+	a.Eq(count, uint64(0), "should be empty")
+}
+
+func TestLabelEscape(t *testing.T) {
+	a := assert.A(t)
+	r := metrics.New()
+	c := r.NewCounter("test_total", "test counter", "entry")
+	c.Inc("a\"b\\c\nd\té")
+
+	out := string(r.Bytes())
+	a.Eq(strings.Contains(out, `test_total{entry="a\"b\\c\nd`+"\té"+`"} 1`), true, "should escape only backslash, quote and newline")
+}
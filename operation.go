@@ -11,23 +11,37 @@ import (
 	"path"
 	"reflect"
 	"runtime"
-	"sync"
+	"strconv"
+
+	"github.com/hexasoftware/flow/registry"
 )
 
 type executorFunc func(*Session, ...Data) (Data, error)
 
 // Operation interface
-type Operation interface { // Id perhaps?
+type Operation interface {
 	Process(params ...Data) (Data, error)
+
+	// Introspection
+	ID() int
+	Kind() string
+	Name() string
+	Inputs() []Operation
+	Source() (file string, line int)
 }
 
 type operation struct {
-	sync.Mutex
 	flow     *Flow
+	id       int // creation order within the flow
+	version  int // incremented when the operation or its inputs are edited
 	name     string
 	kind     string
 	inputs   []*operation // still figuring, might be Operation
 	executor executorFunc // the executor?
+	param    *Param       // named input declaration
+	frozen   bool         // merged, folded or pruned by Optimize
+	entryTag []string     // registry entry tags, set by makeExecutor
+	labels   []string     // metric labels, resolved when built
 
 	// Debug information for each operation
 	file string
@@ -37,9 +51,10 @@ type operation struct {
 // NewOperation creates an operation
 func (f *Flow) newOperation(kind string, inputs []*operation) *operation {
 	_, file, line, _ := runtime.Caller(2) // outside of operation.go?
-	return &operation{
-		Mutex:  sync.Mutex{},
+	f.lastID++
+	op := &operation{
 		flow:   f,
+		id:     f.lastID,
 		kind:   kind,
 		inputs: inputs,
 
@@ -47,6 +62,9 @@ func (f *Flow) newOperation(kind string, inputs []*operation) *operation {
 		line: line,
 		//name:   fmt.Sprintf("(var)<%s>", name),
 	}
+	op.setLabels()
+	f.operations = append(f.operations, op)
+	return op
 
 }
 func (o *operation) String() string {
@@ -54,10 +72,21 @@ func (o *operation) String() string {
 	return fmt.Sprintf("[%s:%d]:{%s,%s}", file, o.line, o.kind, o.name)
 }
 
+// tags returns the registry tags of the entry behind the operation
+func (o *operation) tags() []string {
+	if o.kind != "func" {
+		return nil
+	}
+	return o.entryTag
+}
+
 // Process the operation with a new session
 // ginputs are the global inputs
 func (o *operation) Process(ginputs ...Data) (Data, error) {
-	s := o.flow.NewSession()
+	o.flow.mu.RLock()
+	defer o.flow.mu.RUnlock()
+	s, export := o.flow.NewSession().startTrace()
+	defer export()
 	return s.run(o, ginputs...)
 }
 
@@ -66,6 +95,7 @@ func (f *Flow) Var(name string, initial Data) Operation {
 	inputs := f.makeInputs(initial)
 
 	op := f.newOperation("var", inputs)
+	op.name = name
 	op.executor = func(sess *Session, ginputs ...Data) (Data, error) {
 		if name == "" {
 			return nil, errors.New("Invalid name")
@@ -94,6 +124,7 @@ func (f *Flow) Var(name string, initial Data) Operation {
 func (f *Flow) SetVar(name string, data Data) Operation {
 	inputs := f.makeInputs(data)
 	op := f.newOperation("setvar", inputs)
+	op.name = name
 	op.executor = func(sess *Session, ginputs ...Data) (Data, error) {
 		if name == "" {
 			return nil, errors.New("Invalid name")
@@ -119,11 +150,40 @@ func (f *Flow) Op(name string, params ...interface{}) Operation {
 	if err != nil {
 		return f.ErrOp(err)
 	}
+	entry, _ := f.registry.Entry(name)
 	op := f.newOperation("func", inputs)
 	op.name = name
 	// make executor from registry func
-	op.executor = makeExecutor(op, registryFn)
-	f.operations = append(f.operations, op)
+	op.executor = makeExecutor(op, registryFn, entry)
+	return op
+}
+
+// Factory operation of a factory entry, the operation func is built once
+// from the entry config params and called with params on each run
+func (f *Flow) Factory(name string, config []Data, params ...interface{}) Operation {
+	inputs := f.makeInputs(params...)
+
+	registryFn, err := f.registry.Get(name, config...)
+	if err != nil {
+		return f.ErrOp(err)
+	}
+	entry, _ := f.registry.Entry(name)
+	op := f.newOperation("func", inputs)
+	op.name = name
+	op.executor = makeExecutor(op, registryFn, entry)
+	return op
+}
+
+// Func operation calling fn directly without a registry entry, name is
+// used in traces and metrics
+func (f *Flow) Func(name string, fn interface{}, params ...interface{}) Operation {
+	if fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return f.ErrOp(ErrNotAFunc)
+	}
+	inputs := f.makeInputs(params...)
+	op := f.newOperation("func", inputs)
+	op.name = name
+	op.executor = makeExecutor(op, fn, nil)
 	return op
 }
 
@@ -151,13 +211,15 @@ func (f *Flow) Const(value Data) Operation {
 	}
 
 	op := f.newOperation("const", nil)
-	op.executor = func(*Session, ...Data) (Data, error) { return f.consts[constID], nil }
+	value = f.consts[constID]
+	op.executor = func(*Session, ...Data) (Data, error) { return value, nil }
 	return op
 }
 
 // In define input operation
 func (f *Flow) In(paramID int) Operation {
 	op := f.newOperation("in", nil)
+	op.name = strconv.Itoa(paramID)
 	op.executor = func(sess *Session, ginputs ...Data) (Data, error) {
 		if paramID < 0 || paramID >= len(ginputs) {
 			return nil, ErrInput
@@ -181,10 +243,16 @@ func (f *Flow) makeInputs(params ...Data) []*operation {
 	return inputs
 }
 
-// make any go func as an executor
-func makeExecutor(op *operation, fn interface{}) executorFunc {
-	fnval := reflect.ValueOf(fn)
-	callParam := make([]reflect.Value, fnval.Type().NumIn())
+// make any go func as an executor, inputs are checked against the input
+// constraints of entry if not nil
+func makeExecutor(op *operation, fn interface{}, entry *registry.Entry) executorFunc {
+	// typed adapter if registered, reflection otherwise
+	call := registry.CallerOf(fn)
+	op.entryTag = nil
+	if entry != nil {
+		op.entryTag = entry.Description.Tags
+	}
+	op.setLabels()
 
 	// ExecutorFunc
 	return func(sess *Session, ginputs ...Data) (Data, error) {
@@ -193,35 +261,13 @@ func makeExecutor(op *operation, fn interface{}) executorFunc {
 		if err != nil {
 			return nil, err
 		}
-		// If the fn is a special we execute directly
-		if gFn, ok := fn.(func(...Data) (Data, error)); ok {
-			return gFn(inRes...)
-		}
-
-		for i, r := range inRes {
-			if r == nil {
-				callParam[i] = reflect.Zero(fnval.Type().In(i))
-			} else {
-				callParam[i] = reflect.ValueOf(r)
+		if entry != nil {
+			for i, v := range inRes {
+				if err := entry.ValidateInput(i, v); err != nil {
+					return nil, err
+				}
 			}
 		}
-
-		// Start again and execute function
-		fnret := fnval.Call(callParam)
-		if len(fnret) == 0 {
-			return nil, nil
-		}
-		// Output erroring
-		if len(fnret) > 1 && (fnret[len(fnret)-1].Interface() != nil) {
-			err, ok := fnret[len(fnret)-1].Interface().(error)
-			if !ok {
-				err = errors.New("unknown error")
-			}
-			return nil, err
-		}
-
-		// THE RESULT
-		ret := fnret[0].Interface()
-		return ret, nil
+		return call(inRes...)
 	}
 }
@@ -0,0 +1,182 @@
+package flow
+
+import (
+	"reflect"
+)
+
+// OptimizeStats number of operations changed by Optimize
+type OptimizeStats struct {
+	Merged int // identical pure operations merged
+	Folded int // pure operations with constant inputs replaced by their result
+	Pruned int // operations not reachable from the requested ops
+}
+
+// Optimize merges identical pure operations, folds pure operations that
+// only depend on constants and prunes operations not reachable from ops,
+// every operation is kept usable, merged operations become aliases of the
+// operation they were merged with and folded operations become constants.
+// Operations merged, folded or pruned can't be edited afterwards, edits
+// return ErrOptimized
+func (f *Flow) Optimize(ops ...Operation) OptimizeStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := OptimizeStats{}
+
+	roots := make([]*operation, len(ops))
+	for i, op := range ops {
+		roots[i] = op.(*operation)
+	}
+	if len(roots) == 0 {
+		roots = f.operations
+	}
+
+	// Topological order, inputs first
+	order := []*operation{}
+	visited := map[*operation]bool{}
+	walk(roots, func(op *operation) error {
+		visited[op] = true
+		order = append(order, op)
+		return nil
+	})
+
+	canonical := map[*operation]*operation{}
+	consts := []*operation{}
+	funcs := map[string][]*operation{}
+
+	for _, op := range order {
+		for i, in := range op.inputs {
+			if c, ok := canonical[in]; ok {
+				op.inputs[i] = c
+			}
+		}
+		if op.kind == "const" {
+			if c := findConst(consts, op); c != nil {
+				canonical[op] = c
+				op.frozen, c.frozen = true, true
+				stats.Merged++
+				continue
+			}
+			consts = append(consts, op)
+			continue
+		}
+		if !op.pure() {
+			continue
+		}
+		if c := findFunc(funcs[op.name], op); c != nil {
+			canonical[op] = c
+			op.delegate(c)
+			op.frozen, c.frozen = true, true
+			stats.Merged++
+			continue
+		}
+		funcs[op.name] = append(funcs[op.name], op)
+
+		if op.foldable() && f.fold(op) {
+			stats.Folded++
+			consts = append(consts, op)
+		}
+	}
+
+	// kinds and inputs changed, recompute record identities
+	f.identities.Range(func(k, _ interface{}) bool {
+		f.identities.Delete(k)
+		return true
+	})
+
+	// Prune
+	if len(ops) > 0 {
+		kept := []*operation{}
+		for _, op := range f.operations {
+			if !visited[op] {
+				stats.Pruned++
+				op.frozen = true
+				f.pruned = append(f.pruned, op)
+				continue
+			}
+			kept = append(kept, op)
+		}
+		f.operations = kept
+	}
+	return stats
+}
+
+// pure operation from a pure registry entry
+func (o *operation) pure() bool {
+	if o.kind != "func" {
+		return false
+	}
+	e, err := o.flow.registry.Entry(o.name)
+	return err == nil && e.Pure
+}
+
+func (o *operation) foldable() bool {
+	for _, in := range o.inputs {
+		if in.kind != "const" {
+			return false
+		}
+	}
+	return true
+}
+
+// delegate turns o into an alias running target instead, both share the
+// session result, target is kept as the only input so walks and edits
+// still reach it
+func (o *operation) delegate(target *operation) {
+	o.kind = "alias"
+	o.inputs = []*operation{target}
+	o.setLabels()
+	o.executor = func(sess *Session, ginputs ...Data) (Data, error) {
+		return sess.run(target, ginputs...)
+	}
+}
+
+// fold executes op and turns it into a const of its result, the execution
+// doesn't trigger hooks, metrics or records
+func (f *Flow) fold(op *operation) bool {
+	s := f.NewSession()
+	s.quiet = true
+	res, err := s.run(op)
+	if err != nil {
+		return false
+	}
+	op.kind = "const"
+	op.inputs = nil
+	op.frozen = true
+	op.setLabels()
+	op.executor = func(*Session, ...Data) (Data, error) { return res, nil }
+	return true
+}
+
+func constValue(op *operation) Data {
+	v, _ := op.executor(nil)
+	return v
+}
+
+func findConst(consts []*operation, op *operation) *operation {
+	v := constValue(op)
+	for _, c := range consts {
+		if reflect.DeepEqual(constValue(c), v) {
+			return c
+		}
+	}
+	return nil
+}
+
+func findFunc(funcs []*operation, op *operation) *operation {
+	for _, c := range funcs {
+		if len(c.inputs) != len(op.inputs) {
+			continue
+		}
+		same := true
+		for i := range c.inputs {
+			if c.inputs[i] != op.inputs[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package flow_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestOptimizeMerge(t *testing.T) {
+	a := assert.A(t)
+	calls := 0
+	r := registry.New()
+	r.Add("add", func(a, b int) int { calls++; return a + b }).Pure()
+	f := flow.New().UseRegistry(r)
+
+	in := f.In(0)
+	a1 := f.Op("add", in, 1)
+	a2 := f.Op("add", in, 1)
+	res := f.Op("add", a1, a2)
+
+	stats := f.Optimize(res)
+	a.Eq(stats.Merged, 2, "should merge the duplicated op and const")
+	a.Eq(stats.Folded, 0, "nothing to fold")
+	a.Eq(a2.Kind(), "alias", "merged op should be an alias")
+
+	v, err := res.Process(1)
+	a.Eq(err, nil, "should not error")
+	a.Eq(v, 4, "result should not change")
+	a.Eq(calls, 2, "merged op should run once")
+
+	v, err = a2.Process(2)
+	a.Eq(err, nil, "merged op should still be usable")
+	a.Eq(v, 3, "merged op should return the same result")
+}
+
+func TestOptimizeFold(t *testing.T) {
+	a := assert.A(t)
+	calls := 0
+	r := registry.New()
+	r.Add("add", func(a, b int) int { calls++; return a + b }).Pure()
+	r.Add("impure", func(a int) int { calls++; return a })
+	f := flow.New().UseRegistry(r)
+
+	c := f.Op("add", f.Op("add", 1, 2), 3)
+	res := f.Op("add", c, f.In(0))
+	imp := f.Op("impure", 1)
+
+	stats := f.Optimize(res)
+	a.Eq(stats.Folded, 2, "should fold constant subgraph")
+	a.Eq(stats.Pruned, 2, "should prune unreachable op and its const")
+	a.Eq(calls, 2, "folding should run at optimize time")
+
+	v, err := res.Process(4)
+	a.Eq(err, nil, "should not error")
+	a.Eq(v, 10, "result should not change")
+	a.Eq(calls, 3, "only the input dependent op should run")
+
+	v, _ = imp.Process()
+	a.Eq(v, 1, "pruned op should still be usable")
+}
+
+func TestOptimizeFoldQuiet(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b }).Pure()
+	f := flow.New().UseRegistry(r)
+	hooked := 0
+	f.Hook(flow.Hook{Any: func(string, flow.Operation, time.Time, ...interface{}) { hooked++ }})
+	buf := bytes.NewBuffer(nil)
+	f.UseRecorder(flow.NewRecorder(buf))
+
+	res := f.Op("add", f.Op("add", 1, 2), 3)
+	stats := f.Optimize(res)
+	a.Eq(stats.Folded, 2, "should fold")
+	a.Eq(hooked, 0, "folding should not trigger hooks")
+	a.Eq(buf.Len(), 0, "folding should not be recorded")
+}
+
+func TestOptimizeAlias(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b }).Pure()
+	f := flow.New().UseRegistry(r)
+
+	in := f.In(0)
+	a1 := f.Op("add", in, 1)
+	a2 := f.Op("add", in, 1)
+	res := f.Op("add", a1, a2)
+	f.Optimize(res)
+
+	a.Eq(a1.Kind(), "func", "kept op should not change")
+	a.Eq(a2.Kind(), "alias", "merged op should be an alias")
+	a.Eq(a2.Name(), "add", "alias should keep the entry name")
+	a.Eq(a2.Inputs(), []flow.Operation{a1}, "alias should have the kept op as only input")
+
+	a.Eq(f.SetInput(a1, 1, 5), flow.ErrOptimized, "should not edit the kept op")
+	v, err := res.Process(1)
+	a.Eq(err, nil, "should not error")
+	a.Eq(v, 4, "(1+1)+(1+1)")
+}
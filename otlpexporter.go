@@ -0,0 +1,98 @@
+package flow
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OTLPFileExporter writes each run as a single line of OTLP/JSON
+// (ExportTraceServiceRequest), the format read by the OpenTelemetry
+// collector file receiver
+type OTLPFileExporter struct {
+	sync.Mutex
+	w       io.Writer
+	Service string
+}
+
+// NewOTLPFileExporter creates an exporter writing to w
+func NewOTLPFileExporter(w io.Writer) *OTLPFileExporter {
+	return &OTLPFileExporter{w: w, Service: "flow"}
+}
+
+// Export encode spans as a json line
+func (e *OTLPFileExporter) Export(spans []*Span) error {
+	e.Lock()
+	defer e.Unlock()
+
+	otlpSpans := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		otlpSpans[i] = otlpSpan{
+			TraceID:      s.TraceID,
+			SpanID:       s.SpanID,
+			ParentSpanID: s.ParentID,
+			Name:         s.Name,
+			Kind:         1, // SPAN_KIND_INTERNAL
+			Start:        strconv.FormatInt(s.Start.UnixNano(), 10),
+			End:          strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes: []otlpAttr{
+				otlpString("flow.kind", s.Kind),
+				otlpString("flow.tags", strings.Join(s.Tags, ",")),
+				otlpString("code.filepath", s.File),
+				otlpInt("code.lineno", int64(s.Line)),
+				otlpInt("flow.wait_ns", int64(s.Wait)),
+				otlpInt("flow.exec_ns", int64(s.Exec)),
+			},
+		}
+		if s.Err != nil {
+			otlpSpans[i].Status = &otlpStatus{Code: 2, Message: s.Err.Error()}
+		}
+	}
+
+	req := obj{
+		"resourceSpans": []obj{{
+			"resource": obj{
+				"attributes": []otlpAttr{otlpString("service.name", e.Service)},
+			},
+			"scopeSpans": []obj{{
+				"scope": obj{"name": "github.com/hexasoftware/flow"},
+				"spans": otlpSpans,
+			}},
+		}},
+	}
+	return json.NewEncoder(e.w).Encode(req)
+}
+
+type obj = map[string]interface{}
+
+type otlpSpan struct {
+	TraceID      string      `json:"traceId"`
+	SpanID       string      `json:"spanId"`
+	ParentSpanID string      `json:"parentSpanId,omitempty"`
+	Name         string      `json:"name"`
+	Kind         int         `json:"kind"`
+	Start        string      `json:"startTimeUnixNano"`
+	End          string      `json:"endTimeUnixNano"`
+	Attributes   []otlpAttr  `json:"attributes"`
+	Status       *otlpStatus `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type otlpAttr struct {
+	Key   string `json:"key"`
+	Value obj    `json:"value"`
+}
+
+func otlpString(k, v string) otlpAttr {
+	return otlpAttr{k, obj{"stringValue": v}}
+}
+func otlpInt(k string, v int64) otlpAttr {
+	// OTLP/JSON encodes 64bit integers as strings
+	return otlpAttr{k, obj{"intValue": strconv.FormatInt(v, 10)}}
+}
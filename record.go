@@ -0,0 +1,229 @@
+package flow
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Record of a single operation execution
+type Record struct {
+	ID       int               `json:"id"`  // operation creation order in the flow
+	Key      string            `json:"key"` // kind, name and inputs identity
+	Kind     string            `json:"kind"`
+	Name     string            `json:"name,omitempty"`
+	Inputs   []json.RawMessage `json:"inputs,omitempty"`
+	Output   json.RawMessage   `json:"output,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Lossy    bool              `json:"lossy,omitempty"` // output not recorded, it doesn't survive encoding
+	Start    time.Time         `json:"start"`
+	Duration time.Duration     `json:"duration"`
+}
+
+// Recorder writes every operation execution as a json line, values that
+// don't decode back to an equal value of the same type are not recorded,
+// their records are marked Lossy and replay executes those operations
+type Recorder struct {
+	sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewRecorder creates a recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// UseRecorder records every operation executed by the flow sessions
+func (f *Flow) UseRecorder(r *Recorder) *Flow {
+	f.recorder = r
+	return f
+}
+
+// Err returns the first error encoding a value, values failing to encode
+// are left out of the records
+func (r *Recorder) Err() error {
+	r.Lock()
+	defer r.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(op *operation, inputs []Data, res Data, err error, start time.Time) {
+	rec := Record{
+		ID:       op.id,
+		Key:      op.flow.identity(op),
+		Kind:     op.kind,
+		Name:     op.name,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+	var encErr error
+	rec.Output, encErr = encodeValue(res)
+	rec.Lossy = encErr != nil
+	for _, in := range inputs {
+		data, err := encodeValue(in)
+		if err != nil && encErr == nil {
+			encErr = err
+		}
+		rec.Inputs = append(rec.Inputs, data)
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	r.Lock()
+	defer r.Unlock()
+	if encErr != nil && r.err == nil {
+		r.err = fmt.Errorf("record %v: %v", op, encErr)
+	}
+	r.enc.Encode(rec)
+}
+
+// cachedIdentity operation identity for an operation version
+type cachedIdentity struct {
+	version int
+	id      string
+}
+
+// identity of op independent of the creation order, a hash of the kind,
+// name and inputs identities, consts are identified by value. Identities
+// are cached until the operation is edited or the flow optimized
+func (f *Flow) identity(op *operation) string {
+	if v, ok := f.identities.Load(op); ok && v.(cachedIdentity).version == op.version {
+		return v.(cachedIdentity).id
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s(%s)", op.kind, op.name)
+	if op.kind == "const" {
+		v, _ := op.executor(nil)
+		if data, err := json.Marshal(v); err == nil {
+			h.Write(data)
+		} else {
+			fmt.Fprintf(h, "%#v", v)
+		}
+	}
+	for _, in := range op.inputs {
+		fmt.Fprintf(h, "[%s]", f.identity(in))
+	}
+	id := strconv.FormatUint(h.Sum64(), 16)
+	f.identities.Store(op, cachedIdentity{op.version, id})
+	return id
+}
+
+// encodeValue encodes v as json, values that don't decode back to an
+// equal value of the same type are rejected so replay never returns a
+// value of another type
+func encodeValue(v Data) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := reflect.New(reflect.TypeOf(v))
+	if err := json.Unmarshal(data, dec.Interface()); err != nil || !reflect.DeepEqual(dec.Elem().Interface(), v) {
+		return nil, fmt.Errorf("%T does not survive json encoding", v)
+	}
+	return data, nil
+}
+
+// Recording a loaded set of records
+type Recording struct {
+	Records []Record
+}
+
+// LoadRecording reads records written by a Recorder
+func LoadRecording(rd io.Reader) (*Recording, error) {
+	rec := &Recording{}
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(nil, 64*1024*1024)
+	for sc.Scan() {
+		r := Record{}
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, err
+		}
+		rec.Records = append(rec.Records, r)
+	}
+	return rec, sc.Err()
+}
+
+// UseReplay substitutes the execution of ops with the recorded results,
+// if no ops are given every registry operation is replayed, output values
+// are decoded into the registry entry output type.
+//
+// Records are matched by operation identity, the kind, name and inputs of
+// the operation, so flows built in a different order or edited still
+// replay the unchanged operations, operations without a matching record
+// are executed, as well as operations whose output was not recorded
+func (f *Flow) UseReplay(rec *Recording, ops ...Operation) *Flow {
+	rp := &replay{records: map[string][]Record{}}
+	for _, r := range rec.Records {
+		rp.records[r.Key] = append(rp.records[r.Key], r)
+	}
+	if len(ops) > 0 {
+		rp.selected = map[*operation]bool{}
+		for _, op := range ops {
+			rp.selected[op.(*operation)] = true
+		}
+	}
+	f.replay = rp
+	return f
+}
+
+type replay struct {
+	sync.Mutex
+	records  map[string][]Record // by operation identity
+	selected map[*operation]bool // nil means every func op
+}
+
+// next returns the next recorded result for op, the last record is
+// repeated once every record of op was replayed
+func (rp *replay) next(op *operation) (Record, bool) {
+	if rp.selected != nil && !rp.selected[op] {
+		return Record{}, false
+	}
+	if rp.selected == nil && op.kind != "func" {
+		return Record{}, false
+	}
+	key := op.flow.identity(op)
+	rp.Lock()
+	defer rp.Unlock()
+	recs := rp.records[key]
+	if len(recs) == 0 {
+		return Record{}, false
+	}
+	r := recs[0]
+	if r.Kind != op.kind || r.Name != op.name || r.Lossy {
+		return Record{}, false
+	}
+	if len(recs) > 1 {
+		rp.records[key] = recs[1:]
+	}
+	return r, true
+}
+
+// result decodes the recorded output
+func (rp *replay) result(op *operation, r Record) (Data, error) {
+	if r.Error != "" {
+		return nil, errors.New(r.Error)
+	}
+	if len(r.Output) == 0 || string(r.Output) == "null" {
+		return nil, nil
+	}
+	if e, err := op.flow.registry.Entry(op.name); err == nil && op.kind == "func" && e.Output != nil {
+		v := reflect.New(e.Output)
+		if err := json.Unmarshal(r.Output, v.Interface()); err == nil {
+			return v.Elem().Interface(), nil
+		}
+	}
+	var v Data
+	err := json.Unmarshal(r.Output, &v)
+	return v, err
+}
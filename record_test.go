@@ -0,0 +1,146 @@
+package flow_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func buildRecordFlow(r *registry.R) (*flow.Flow, flow.Operation, flow.Operation) {
+	f := flow.New().UseRegistry(r)
+	next := f.Op("next")
+	return f, next, f.Op("add", next, 10)
+}
+
+func TestRecordReplay(t *testing.T) {
+	a := assert.A(t)
+	buf := bytes.NewBuffer(nil)
+
+	calls := 0
+	r := registry.New()
+	r.Add("next", func() int { calls++; return calls })
+	r.Add("add", func(a, b int) int { return a + b })
+	f, _, add := buildRecordFlow(r)
+	f.UseRecorder(flow.NewRecorder(buf))
+	res, err := add.Process()
+	a.Eq(err, nil, "recording run should not error")
+	a.Eq(res, 11, "first run")
+
+	rec, err := flow.LoadRecording(buf)
+	a.Eq(err, nil, "should load recording")
+	a.Eq(len(rec.Records) > 0, true, "should have records")
+
+	calls = 100
+	f, _, add = buildRecordFlow(r)
+	f.UseReplay(rec)
+	res, err = add.Process()
+	a.Eq(err, nil, "replay should not error")
+	a.Eq(res, 11, "replay should return the recorded result")
+	a.Eq(calls, 100, "replayed ops should not execute")
+}
+
+func TestReplaySelected(t *testing.T) {
+	a := assert.A(t)
+	buf := bytes.NewBuffer(nil)
+
+	calls := 0
+	r := registry.New()
+	r.Add("next", func() int { calls++; return calls })
+	r.Add("add", func(a, b int) int { return a + b })
+	f, _, add := buildRecordFlow(r)
+	f.UseRecorder(flow.NewRecorder(buf))
+	add.Process()
+
+	rec, err := flow.LoadRecording(buf)
+	a.Eq(err, nil, "should load recording")
+
+	f, next, add := buildRecordFlow(r)
+	f.UseReplay(rec, next)
+	res, err := add.Process()
+	a.Eq(err, nil, "replay should not error")
+	a.Eq(res, 11, "selected op should be replayed")
+	a.Eq(calls, 1, "next should not execute again")
+}
+
+func TestReplayError(t *testing.T) {
+	a := assert.A(t)
+	buf := bytes.NewBuffer(nil)
+
+	r := registry.New()
+	r.Add("fail", func(a int) (int, error) { return 0, errors.New("failed") })
+	f := flow.New().UseRegistry(r).UseRecorder(flow.NewRecorder(buf))
+	_, err := f.Op("fail", 1).Process()
+	a.NotEq(err, nil, "should fail")
+
+	rec, err := flow.LoadRecording(buf)
+	a.Eq(err, nil, "should load recording")
+	a.Eq(rec.Records[len(rec.Records)-1].Error, "failed", "should record the error")
+	a.Eq(string(rec.Records[len(rec.Records)-1].Inputs[0]), "1", "should record the inputs")
+
+	f = flow.New().UseRegistry(r).UseReplay(rec)
+	_, err = f.Op("fail", 1).Process()
+	a.Eq(err, errors.New("failed"), "should replay the error")
+}
+
+func TestReplayIdentity(t *testing.T) {
+	a := assert.A(t)
+	buf := bytes.NewBuffer(nil)
+
+	calls := 0
+	r := registry.New()
+	r.Add("next", func() int { calls++; return calls })
+	r.Add("add", func(a, b int) int { return a + b })
+	f, _, add := buildRecordFlow(r)
+	f.UseRecorder(flow.NewRecorder(buf))
+	add.Process()
+
+	rec, err := flow.LoadRecording(buf)
+	a.Eq(err, nil, "should load recording")
+
+	// built in another order with a new operation
+	calls = 100
+	f = flow.New().UseRegistry(r).UseReplay(rec)
+	other := f.Op("add", 1, 2)
+	next := f.Op("next")
+	add = f.Op("add", next, 10)
+	res, err := add.Process()
+	a.Eq(err, nil, "replay should not error")
+	a.Eq(res, 11, "should replay the matching operation")
+	a.Eq(calls, 100, "replayed ops should not execute")
+
+	res, err = other.Process()
+	a.Eq(err, nil, "unrecorded op should not error")
+	a.Eq(res, 3, "unrecorded op should execute")
+
+	res, _ = f.Op("add", next, 20).Process()
+	a.Eq(res, 21, "op with different inputs should execute")
+}
+
+type opaque struct{ v int }
+
+func TestRecordLossy(t *testing.T) {
+	a := assert.A(t)
+	buf := bytes.NewBuffer(nil)
+
+	calls := 0
+	r := registry.New()
+	r.Add("opaque", func() opaque { calls++; return opaque{calls} })
+	rc := flow.NewRecorder(buf)
+	f := flow.New().UseRegistry(r).UseRecorder(rc)
+	_, err := f.Op("opaque").Process()
+	a.Eq(err, nil, "should not error")
+	a.NotEq(rc.Err(), nil, "should report values that can't be recorded")
+
+	rec, err := flow.LoadRecording(buf)
+	a.Eq(err, nil, "should load recording")
+	a.Eq(rec.Records[0].Lossy, true, "should mark the record")
+
+	f = flow.New().UseRegistry(r).UseReplay(rec)
+	res, err := f.Op("opaque").Process()
+	a.Eq(err, nil, "should not error")
+	a.Eq(res, opaque{2}, "lossy records should be executed")
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Caller calls a registered function with params, returning the first
+// result or the error if the last result is a non nil error
+type Caller func(params ...interface{}) (interface{}, error)
+
+// Adapter returns a typed Caller for functions of the type it was
+// registered with, usually generated by cmd/adaptergen
+type Adapter func(fn interface{}) Caller
+
+var adapters sync.Map // reflect.Type to Adapter
+
+// RegisterAdapter registers the adapter for functions of type typ
+func RegisterAdapter(typ reflect.Type, a Adapter) {
+	adapters.Store(typ, a)
+}
+
+// CallerOf returns a Caller for fn, using a registered adapter for the fn
+// type if any, reflection otherwise
+func CallerOf(fn interface{}) Caller {
+	if c, ok := fn.(func(...interface{}) (interface{}, error)); ok {
+		return c
+	}
+	if a, ok := adapters.Load(reflect.TypeOf(fn)); ok {
+		return a.(Adapter)(fn)
+	}
+	return reflectCaller(fn)
+}
+
+func reflectCaller(fn interface{}) Caller {
+	fnval := reflect.ValueOf(fn)
+	fntyp := fnval.Type()
+	nIn := fntyp.NumIn()
+	return func(params ...interface{}) (interface{}, error) {
+		if fntyp.IsVariadic() {
+			if len(params) < nIn-1 {
+				return nil, ErrInput
+			}
+		} else if len(params) != nIn {
+			return nil, ErrInput
+		}
+		callParam := make([]reflect.Value, len(params))
+		for i, p := range params {
+			if p != nil {
+				callParam[i] = reflect.ValueOf(p)
+				continue
+			}
+			if fntyp.IsVariadic() && i >= nIn-1 {
+				callParam[i] = reflect.Zero(fntyp.In(nIn - 1).Elem())
+				continue
+			}
+			callParam[i] = reflect.Zero(fntyp.In(i))
+		}
+		fnret := fnval.Call(callParam)
+		if len(fnret) == 0 {
+			return nil, nil
+		}
+		if len(fnret) > 1 && fnret[len(fnret)-1].Interface() != nil {
+			err, ok := fnret[len(fnret)-1].Interface().(error)
+			if !ok {
+				err = errors.New("unknown error")
+			}
+			return nil, err
+		}
+		return fnret[0].Interface(), nil
+	}
+}
@@ -0,0 +1,42 @@
+package registry_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+type adaptTest int
+
+func TestCallerOf(t *testing.T) {
+	a := assert.A(t)
+
+	adapted := 0
+	registry.RegisterAdapter(reflect.TypeOf((func(adaptTest) adaptTest)(nil)), func(fn interface{}) registry.Caller {
+		f := fn.(func(adaptTest) adaptTest)
+		return func(params ...interface{}) (interface{}, error) {
+			adapted++
+			p0, _ := params[0].(adaptTest)
+			return f(p0), nil
+		}
+	})
+
+	double := func(v adaptTest) adaptTest { return v * 2 }
+	res, err := registry.CallerOf(double)(adaptTest(2))
+	a.Eq(err, nil, "should not error")
+	a.Eq(res, adaptTest(4), "should call the function")
+	a.Eq(adapted, 1, "should use the adapter")
+
+	res, err = registry.CallerOf(func(a, b int) int { return a + b })(1, 2)
+	a.Eq(err, nil, "should not error")
+	a.Eq(res, 3, "should fallback to reflection")
+
+	_, err = registry.CallerOf(func(a int) (int, error) { return 0, errors.New("fail") })(nil)
+	a.Eq(err.Error(), "fail", "should return the func error")
+
+	_, err = registry.CallerOf(func(a int) int { return a })()
+	a.Eq(err, registry.ErrInput, "should check the number of params")
+}
@@ -0,0 +1,224 @@
+// Command adaptergen generates typed registry adapters for the functions
+// a package registers with registry.Add, entries with an adapter are called
+// without reflection. Variadic, generic and functions using types not
+// visible from the package are left to reflection.
+//
+// Usage in a package registering entries:
+//
+//	//go:generate go run github.com/hexasoftware/flow/registry/cmd/adaptergen
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hexasoftware/flow/internal/codegen"
+)
+
+func main() {
+	out := flag.String("o", "adapters_gen.go", "output file name within each package dir")
+	flag.Parse()
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	for _, dir := range dirs {
+		src, err := generate(dir, *out)
+		if err != nil {
+			log.Fatalf("%s: %v", dir, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, *out), src, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// generate type checks the package in dir and returns the adapters source
+func generate(dir, outName string) ([]byte, error) {
+	p, err := codegen.Load(dir, outName, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &gen{pkg: p.Types, imports: map[string]string{}, sigs: map[string]*types.Signature{}}
+	p.RegistryAdds(func(_ *ast.File, call *ast.CallExpr) {
+		for _, arg := range call.Args {
+			sig, ok := p.Info.TypeOf(arg).Underlying().(*types.Signature)
+			if !ok {
+				continue
+			}
+			g.add(sig)
+			// factories, flow calls the returned func
+			if sig.Results().Len() > 0 {
+				if fsig, ok := sig.Results().At(0).Type().Underlying().(*types.Signature); ok {
+					g.add(fsig)
+				}
+			}
+		}
+	})
+	return g.source()
+}
+
+type gen struct {
+	pkg     *types.Package
+	imports map[string]string // path to name
+	sigs    map[string]*types.Signature
+}
+
+func (g *gen) add(sig *types.Signature) {
+	if sig.Variadic() || sig.TypeParams().Len() > 0 || !g.visible(sig) {
+		return
+	}
+	res := sig.Results()
+	if res.Len() > 1 && !isError(res.At(res.Len()-1).Type()) {
+		return
+	}
+	g.sigs[g.funcType(sig)] = sig
+}
+
+// visible checks if t can be named from the package
+func (g *gen) visible(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Basic:
+		return true
+	case *types.Alias:
+		obj := t.Obj()
+		if obj.Pkg() != nil && obj.Pkg() != g.pkg && !obj.Exported() {
+			return false
+		}
+		return g.visible(types.Unalias(t))
+	case *types.Named:
+		obj := t.Obj()
+		return obj.Pkg() == nil || obj.Pkg() == g.pkg || obj.Exported()
+	case *types.Pointer:
+		return g.visible(t.Elem())
+	case *types.Slice:
+		return g.visible(t.Elem())
+	case *types.Array:
+		return g.visible(t.Elem())
+	case *types.Chan:
+		return g.visible(t.Elem())
+	case *types.Map:
+		return g.visible(t.Key()) && g.visible(t.Elem())
+	case *types.Signature:
+		for _, tup := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tup.Len(); i++ {
+				if !g.visible(tup.At(i).Type()) {
+					return false
+				}
+			}
+		}
+		return true
+	case *types.Interface:
+		return t.NumMethods() == 0
+	case *types.Struct:
+		return t.NumFields() == 0
+	}
+	return false
+}
+
+func (g *gen) qualifier(p *types.Package) string {
+	if p == g.pkg {
+		return ""
+	}
+	if name, ok := g.imports[p.Path()]; ok {
+		return name
+	}
+	name := p.Name()
+	for i := 1; ; i++ {
+		used := false
+		for _, n := range g.imports {
+			used = used || n == name
+		}
+		if !used {
+			break
+		}
+		name = fmt.Sprintf("%s%d", p.Name(), i)
+	}
+	g.imports[p.Path()] = name
+	return name
+}
+
+func (g *gen) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+// funcType func type without param names
+func (g *gen) funcType(sig *types.Signature) string {
+	params := []string{}
+	for i := 0; i < sig.Params().Len(); i++ {
+		params = append(params, g.typeString(sig.Params().At(i).Type()))
+	}
+	results := []string{}
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, g.typeString(sig.Results().At(i).Type()))
+	}
+	ret := "func(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		ret += " " + results[0]
+	default:
+		ret += " (" + strings.Join(results, ", ") + ")"
+	}
+	return ret
+}
+
+func (g *gen) source() ([]byte, error) {
+	keys := []string{}
+	for k := range g.sigs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no registered functions found")
+	}
+
+	g.qualifier(types.NewPackage("reflect", "reflect"))
+	reg := g.qualifier(types.NewPackage(codegen.RegistryPath, "registry"))
+	body := bytes.NewBuffer(nil)
+	for _, typ := range keys {
+		sig := g.sigs[typ]
+		nIn := sig.Params().Len()
+		fmt.Fprintf(body, "%s.RegisterAdapter(reflect.TypeOf((%s)(nil)), func(fn interface{}) %s.Caller {\n", reg, typ, reg)
+		fmt.Fprintf(body, "f := fn.(%s)\n", typ)
+		fmt.Fprintf(body, "return func(params ...interface{}) (interface{}, error) {\n")
+		fmt.Fprintf(body, "if len(params) != %d {\nreturn nil, %s.ErrInput\n}\n", nIn, reg)
+		args := []string{}
+		for i := 0; i < nIn; i++ {
+			fmt.Fprintf(body, "p%d, ok := params[%d].(%s)\n", i, i, g.typeString(sig.Params().At(i).Type()))
+			fmt.Fprintf(body, "if !ok && params[%d] != nil {\nreturn nil, %s.ErrInput\n}\n", i, reg)
+			args = append(args, fmt.Sprintf("p%d", i))
+		}
+		call := fmt.Sprintf("f(%s)", strings.Join(args, ", "))
+		switch n := sig.Results().Len(); n {
+		case 0:
+			fmt.Fprintf(body, "%s\nreturn nil, nil\n", call)
+		case 1:
+			fmt.Fprintf(body, "return %s, nil\n", call)
+		default:
+			fmt.Fprintf(body, "r, %serr := %s\n", strings.Repeat("_, ", n-2), call)
+			fmt.Fprintf(body, "if err != nil {\nreturn nil, err\n}\nreturn r, nil\n")
+		}
+		fmt.Fprintf(body, "}\n})\n")
+	}
+
+	out := bytes.NewBuffer(nil)
+	fmt.Fprintf(out, "// Code generated by adaptergen. DO NOT EDIT.\n\npackage %s\n\n", g.pkg.Name())
+	codegen.WriteImports(out, g.imports)
+	fmt.Fprintf(out, "\nfunc init() {\n%s}\n", body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
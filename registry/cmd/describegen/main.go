@@ -0,0 +1,274 @@
+// Command describegen generates registry descriptions from the source of
+// the functions a package registers with registry.Add, parameter and named
+// result names, doc comments and "// flow:tags a,b" annotations become a
+// describe func filling the entries description. Functions from other
+// packages are documented from their source, other functions from the
+// comment above the registering line.
+//
+// Usage in a package registering entries, describe(r) must be called once
+// the entries are added, it errors if an entry is missing, descriptions set
+// by hand are kept:
+//
+//	//go:generate go run github.com/hexasoftware/flow/registry/cmd/describegen
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hexasoftware/flow/internal/codegen"
+)
+
+const tagsAnnotation = "flow:tags"
+
+func main() {
+	out := flag.String("o", "describe_gen.go", "output file name within each package dir")
+	fn := flag.String("func", "describe", "name of the generated func")
+	flag.Parse()
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	for _, dir := range dirs {
+		src, err := generate(dir, *out, *fn)
+		if err != nil {
+			log.Fatalf("%s: %v", dir, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, *out), src, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// doc of a registered entry
+type doc struct {
+	name   string
+	desc   string
+	params []string
+	inputs []string
+	output string
+	tags   []string
+}
+
+// generate type checks the package in dir and returns the describe source
+func generate(dir, outName, fnName string) ([]byte, error) {
+	// the package calls the func being generated
+	p, err := codegen.Load(dir, outName, parser.ParseComments, func(err types.Error) bool {
+		return err.Msg == "undefined: "+fnName
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &gen{fset: p.Fset, info: p.Info, decls: map[string]*ast.File{}, docs: map[string]doc{}}
+	p.RegistryAdds(g.addCall)
+	return g.source(p.Types.Name(), fnName)
+}
+
+type gen struct {
+	fset  *token.FileSet
+	info  *types.Info
+	decls map[string]*ast.File // parsed source of other packages
+	docs  map[string]doc
+}
+
+// addCall documents the entries registered by a registry Add call
+func (g *gen) addCall(file *ast.File, call *ast.CallExpr) {
+	name := ""
+	var nameArg ast.Expr
+	for _, arg := range call.Args {
+		tv := g.info.Types[arg]
+		if tv.Value != nil && tv.Value.Kind() == constant.String {
+			name, nameArg = constant.StringVal(tv.Value), arg
+			continue
+		}
+		sig, ok := tv.Type.Underlying().(*types.Signature)
+		if !ok {
+			continue
+		}
+		d := doc{name: name}
+		var comment *ast.CommentGroup
+		switch fn := g.funcObj(arg).(type) {
+		case *types.Func:
+			if fn.Type().(*types.Signature).Recv() != nil {
+				break
+			}
+			if d.name == "" {
+				d.name = fn.Name()
+			}
+			comment = g.funcDoc(fn)
+		}
+		if comment == nil {
+			start := arg
+			if nameArg != nil {
+				start = nameArg
+			}
+			comment = commentAbove(g.fset, file, start)
+		}
+		name, nameArg = "", nil
+		if d.name == "" {
+			continue
+		}
+
+		// factories are configured by their params
+		if sig.Results().Len() > 0 {
+			if fsig, ok := sig.Results().At(0).Type().Underlying().(*types.Signature); ok {
+				for i := 0; i < sig.Params().Len(); i++ {
+					d.params = append(d.params, paramName(sig.Params().At(i).Name()))
+				}
+				sig = fsig
+			}
+		}
+		for i := 0; i < sig.Params().Len(); i++ {
+			d.inputs = append(d.inputs, paramName(sig.Params().At(i).Name()))
+		}
+		if sig.Results().Len() > 0 {
+			d.output = paramName(sig.Results().At(0).Name())
+		}
+		if comment != nil {
+			d.desc, d.tags = parseDoc(comment)
+		}
+		g.docs[d.name] = d
+	}
+}
+
+// funcObj the package level func referenced by expr if any
+func (g *gen) funcObj(expr ast.Expr) types.Object {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return g.info.Uses[e]
+	case *ast.SelectorExpr:
+		if _, ok := g.info.Selections[e]; ok {
+			return nil
+		}
+		return g.info.Uses[e.Sel]
+	}
+	return nil
+}
+
+// funcDoc doc comment of the declaration of fn
+func (g *gen) funcDoc(fn *types.Func) *ast.CommentGroup {
+	pos := g.fset.Position(fn.Pos())
+	if !pos.IsValid() {
+		return nil
+	}
+	f, ok := g.decls[pos.Filename]
+	if !ok {
+		var err error
+		f, err = parser.ParseFile(token.NewFileSet(), pos.Filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil
+		}
+		g.decls[pos.Filename] = f
+	}
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if ok && fd.Recv == nil && fd.Name.Name == fn.Name() {
+			return fd.Doc
+		}
+	}
+	return nil
+}
+
+// commentAbove comment group ending on the line before n
+func commentAbove(fset *token.FileSet, file *ast.File, n ast.Node) *ast.CommentGroup {
+	line := fset.Position(n.Pos()).Line
+	for _, c := range file.Comments {
+		if fset.Position(c.End()).Line == line-1 {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseDoc first paragraph of the comment and flow:tags annotations
+func parseDoc(c *ast.CommentGroup) (string, []string) {
+	tags := []string{}
+	lines := []string{}
+	for _, l := range strings.Split(c.Text(), "\n") {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, tagsAnnotation) {
+			for _, t := range strings.Split(strings.TrimPrefix(l, tagsAnnotation), ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					tags = append(tags, t)
+				}
+			}
+			continue
+		}
+		if l == "" && len(lines) > 0 {
+			break
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, " "), tags
+}
+
+func paramName(name string) string {
+	if name == "_" {
+		return ""
+	}
+	return name
+}
+
+func (g *gen) source(pkgName, fnName string) ([]byte, error) {
+	names := []string{}
+	for k := range g.docs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no registered functions found")
+	}
+
+	out := bytes.NewBuffer(nil)
+	fmt.Fprintf(out, "// Code generated by describegen. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	fmt.Fprintf(out, "import %q\n\n", codegen.RegistryPath)
+	fmt.Fprintf(out, "// %s documents the entries of r from their source, an error means\n", fnName)
+	fmt.Fprintf(out, "// the entries changed since the file was generated\n")
+	fmt.Fprintf(out, "func %s(r *registry.R) error {\n", fnName)
+	for _, name := range names {
+		d := g.docs[name]
+		fmt.Fprintf(out, "if err := r.Document(%q, registry.Doc{\n", name)
+		if d.desc != "" {
+			fmt.Fprintf(out, "Description: %q,\n", d.desc)
+		}
+		if hasName(d.params) {
+			fmt.Fprintf(out, "Params: %#v,\n", d.params)
+		}
+		if hasName(d.inputs) {
+			fmt.Fprintf(out, "Inputs: %#v,\n", d.inputs)
+		}
+		if d.output != "" {
+			fmt.Fprintf(out, "Output: %q,\n", d.output)
+		}
+		if len(d.tags) > 0 {
+			fmt.Fprintf(out, "Tags: %#v,\n", d.tags)
+		}
+		fmt.Fprintf(out, "}); err != nil {\nreturn err\n}\n")
+	}
+	fmt.Fprintf(out, "return nil\n}\n")
+	return format.Source(out.Bytes())
+}
+
+func hasName(names []string) bool {
+	for _, n := range names {
+		if n != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+// Package fixture registers entries documented by the describegen test
+package fixture
+
+import "github.com/hexasoftware/flow/registry"
+
+// New registry of the fixture entries
+func New() *registry.R {
+	r := registry.New()
+	r.Add(Scale, Clamp)
+	// Greet says hello to name.
+	//
+	// Only the first paragraph is used.
+	r.Add("greet", func(name string) string { return "hello " + name })
+	// flow:tags rand
+	r.Add("Seeded", func(seed int64) func(n int) int {
+		return func(n int) int { return int(seed) % n }
+	})
+	r.Add("ignored", func(_ int) {})
+	if err := describe(r); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Scale multiplies x by factor.
+// flow:tags math, scale
+func Scale(x, factor float64) (scaled float64) {
+	return x * factor
+}
+
+// Clamp limits v to [lo, hi].
+func Clamp(v, lo, hi float64) float64 {
+	switch {
+	case v < lo:
+		return lo
+	case v > hi:
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ConvertNumber converts the numeric value v to the numeric type typ,
+// values that would be truncated or overflow typ are rejected
+func ConvertNumber(v reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if !IsNumber(v.Kind()) || !IsNumber(typ.Kind()) {
+		return reflect.Value{}, fmt.Errorf("cannot convert %v to %v", v.Type(), typ)
+	}
+	dst := reflect.New(typ).Elem()
+	lossy := false
+	switch {
+	case isInt(typ.Kind()):
+		switch {
+		case isInt(v.Kind()):
+			lossy = dst.OverflowInt(v.Int())
+		case isUint(v.Kind()):
+			lossy = v.Uint() > math.MaxInt64 || dst.OverflowInt(int64(v.Uint()))
+		default:
+			f := v.Float()
+			lossy = f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f))
+		}
+	case isUint(typ.Kind()):
+		switch {
+		case isInt(v.Kind()):
+			lossy = v.Int() < 0 || dst.OverflowUint(uint64(v.Int()))
+		case isUint(v.Kind()):
+			lossy = dst.OverflowUint(v.Uint())
+		default:
+			f := v.Float()
+			lossy = f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f))
+		}
+	default:
+		lossy = !isInt(v.Kind()) && !isUint(v.Kind()) && dst.OverflowFloat(v.Float())
+	}
+	if lossy {
+		return reflect.Value{}, fmt.Errorf("%v does not fit in %v", v.Interface(), typ)
+	}
+	return v.Convert(typ), nil
+}
+
+// IsNumber reports if k is an integer or float kind, values of these kinds
+// can be converted with ConvertNumber
+func IsNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
@@ -0,0 +1,38 @@
+package registry_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestConvertNumber(t *testing.T) {
+	tests := []struct {
+		v   interface{}
+		typ interface{}
+		ok  bool
+	}{
+		{2.0, 0, true},
+		{2.7, 0, false},
+		{255, uint8(0), true},
+		{256, uint8(0), false},
+		{-1, uint(0), false},
+		{uint64(math.MaxUint64), int64(0), false},
+		{1e300, float32(0), false},
+		{0.1, float32(0), true},
+		{math.NaN(), 0, false},
+		{3, 0.0, true},
+	}
+	for _, tt := range tests {
+		a := assert.A(t)
+		typ := reflect.TypeOf(tt.typ)
+		v, err := registry.ConvertNumber(reflect.ValueOf(tt.v), typ)
+		a.Eq(err == nil, tt.ok, "convert ", tt.v, " to ", typ)
+		if err == nil {
+			a.Eq(v.Type(), typ, "should convert to ", typ)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package registry
 
 // Description of an entry
 type Description struct {
-	Name string   `json:"name"`
-	Desc string   `json:"description"`
-	Tags []string `json:"categories"`
-
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Version   int      `json:"version"`
+	Desc      string   `json:"description"`
+	Tags      []string `json:"categories"`
+
+	// Params configuration of factory entries
+	Params []DescType `json:"params,omitempty"`
 	//InputType
 	Inputs []DescType `json:"inputs"`
 	Output DescType   `json:"output"`
@@ -38,54 +42,161 @@ func (d *EDescriber) Entries() []*Entry {
 	return d.entries
 }
 
-// Description set node description
-func (d *EDescriber) Description(m string) *EDescriber {
-	for _, e := range d.entries {
-		e.Description.Desc = m
+// update applies fn to the entries, registered entries are copied and
+// replaced in their registry notifying watchers
+func (d *EDescriber) update(fn func(e *Entry)) *EDescriber {
+	for i, e := range d.entries {
+		if e.registry == nil {
+			fn(e)
+			continue
+		}
+		d.entries[i] = e.registry.update(e, fn)
 	}
 	return d
+}
 
+// Description set node description
+func (d *EDescriber) Description(m string) *EDescriber {
+	return d.update(func(e *Entry) {
+		e.Description.Desc = m
+	})
 }
 
 //Tags set categories of the group
 func (d *EDescriber) Tags(tags ...string) *EDescriber {
-	for _, e := range d.entries {
+	return d.update(func(e *Entry) {
 		e.Description.Tags = tags
-	}
-	return d
+	})
 }
 
 // Inputs describe inputs
 func (d *EDescriber) Inputs(inputs ...string) *EDescriber {
-	for _, e := range d.entries {
-
+	return d.update(func(e *Entry) {
 		for i, dstr := range inputs {
 			if i >= len(e.Description.Inputs) { // do nothing
 				break // next entry
 			}
-			curDesc := e.Description.Inputs[i]
-			e.Description.Inputs[i] = DescType{curDesc.Type, dstr}
+			e.Description.Inputs[i].Name = dstr
 		}
-	}
-	return d
+	})
+}
+
+// Params describe the configuration params of factory entries
+func (d *EDescriber) Params(params ...string) *EDescriber {
+	return d.update(func(e *Entry) {
+		for i, dstr := range params {
+			if i >= len(e.Description.Params) {
+				break
+			}
+			e.Description.Params[i].Name = dstr
+		}
+	})
 }
 
 // Output describe the output
 func (d *EDescriber) Output(output string) *EDescriber {
-	for _, e := range d.entries {
-		e.Description.Output = DescType{e.Description.Output.Type, output}
-	}
-	return d
+	return d.update(func(e *Entry) {
+		e.Description.Output.Name = output
+	})
 }
 
 // Extra set extras of the group
 func (d *EDescriber) Extra(name string, value interface{}) *EDescriber {
-	for _, e := range d.entries {
+	return d.update(func(e *Entry) {
 		e.Description.Extra[name] = value
+	})
+}
+
+// Remote mark entries to be executed by the registry dispatcher
+func (d *EDescriber) Remote() *EDescriber {
+	return d.update(func(e *Entry) {
+		e.Remote = true
+	})
+}
+
+// Pure mark entries as deterministic without side effects
+func (d *EDescriber) Pure() *EDescriber {
+	return d.update(func(e *Entry) {
+		e.Pure = true
+	})
+}
+
+// Doc entry documentation from source, usually generated by cmd/describegen
+type Doc struct {
+	Description string
+	Params      []string
+	Inputs      []string
+	Output      string
+	Tags        []string
+}
+
+// Document fills the description of entry name with doc, descriptions,
+// names and tags already set are kept
+func (r *R) Document(name string, doc Doc) error {
+	e, err := r.Entry(name)
+	if err != nil {
+		return err
+	}
+	r.update(e, func(e *Entry) { e.Description.document(doc) })
+	return nil
+}
+
+func (d *Description) document(doc Doc) {
+	if d.Desc == "" {
+		d.Desc = doc.Description
+	}
+	for i, p := range doc.Params {
+		if i < len(d.Params) && d.Params[i].Name == "" {
+			d.Params[i].Name = p
+		}
+	}
+	for i, in := range doc.Inputs {
+		if i < len(d.Inputs) && d.Inputs[i].Name == "" {
+			d.Inputs[i].Name = in
+		}
+	}
+	if d.Output.Name == "" {
+		d.Output.Name = doc.Output
 	}
+	if len(doc.Tags) > 0 && (len(d.Tags) == 0 || len(d.Tags) == 1 && d.Tags[0] == "generic") {
+		d.Tags = doc.Tags
+	}
+}
+
+// clone copies the description with its own types, schemas, tags and
+// extras so the copy can be changed without affecting d
+func (d Description) clone() Description {
+	d.Tags = append([]string(nil), d.Tags...)
+	d.Params = cloneDescTypes(d.Params)
+	d.Inputs = cloneDescTypes(d.Inputs)
+	d.Output = d.Output.clone()
+	extra := make(map[string]interface{}, len(d.Extra))
+	for k, v := range d.Extra {
+		extra[k] = v
+	}
+	d.Extra = extra
 	return d
 }
 
+func cloneDescTypes(types []DescType) []DescType {
+	if types == nil {
+		return nil
+	}
+	ret := make([]DescType, len(types))
+	for i, t := range types {
+		ret[i] = t.clone()
+	}
+	return ret
+}
+
+func (t DescType) clone() DescType {
+	if t.Schema != nil {
+		s := *t.Schema
+		t.Schema = &s
+	}
+	return t
+}
+
 /*/ Describer
 type Describer struct {
 	target *Description
@@ -52,3 +52,137 @@ func TestMakeBatch(t *testing.T) {
 	a.NotEq(d.Err, nil, "err should not be nil setting extra")
 
 }*/
+
+func TestDescriberFlags(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+
+	d := r.Add(strings.Split, strings.Join).Pure().Remote()
+	for _, e := range d.Entries() {
+		a.Eq(e.Pure, true, "entry should be pure")
+		a.Eq(e.Remote, true, "entry should be remote")
+	}
+}
+
+func TestDocument(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add(strings.Split).Inputs("", "separator")
+	r.Add(strings.Join).Description("joins").Tags("strings")
+
+	doc := registry.Doc{
+		Description: "from source",
+		Inputs:      []string{"s", "sep"},
+		Output:      "parts",
+		Tags:        []string{"text"},
+	}
+	a.Eq(r.Document("Split", doc), nil, "should document")
+	a.Eq(r.Document("Join", doc), nil, "should document")
+	a.Eq(r.Document("bogus", doc), registry.ErrNotFound, "should not document missing entries")
+
+	e, _ := r.Entry("Split")
+	d := e.Description
+	a.Eq(d.Desc, "from source", "should set the description")
+	a.Eq(d.Inputs[0].Name, "s", "should set empty input names")
+	a.Eq(d.Inputs[1].Name, "separator", "should keep input names")
+	a.Eq(d.Output.Name, "parts", "should set the output name")
+	a.Eq(d.Tags, []string{"text"}, "should replace default tags")
+
+	e, _ = r.Entry("Join")
+	a.Eq(e.Description.Desc, "joins", "should keep the description")
+	a.Eq(e.Description.Tags, []string{"strings"}, "should keep tags")
+}
+
+func TestDescriberInput(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+
+	d := r.Add("train", func(rate float64, act string, epochs int) int { return epochs }).
+		Input(0, "learning rate", registry.Default(0.3), registry.Range(0, 1), registry.Widget("slider")).
+		Input(1, "activation", registry.Enum("sigmoid", "relu"), registry.Default("relu")).
+		Input(2, "epochs", registry.Default(10))
+	a.Eq(d.Err, nil, "should describe inputs")
+
+	e, _ := r.Entry("train")
+	in := e.Description.Inputs
+	a.Eq(in[0].Name, "learning rate", "should set the input name")
+	a.Eq(in[0].Widget, "slider", "should set the widget")
+	a.Eq(*in[0].Schema.Maximum, 1.0, "should set the range")
+	a.Eq(in[1].Widget, "select", "enum should default to select")
+	a.Eq(in[1].Schema.Enum, []interface{}{"sigmoid", "relu"}, "should set the enum")
+
+	v, err := e.DefaultInput(2)
+	a.Eq(err, nil, "should convert the default")
+	a.Eq(v, 10, "default should have the input type")
+	v, _ = e.DefaultInput(0)
+	a.Eq(v, 0.3, "should return the default")
+
+	a.NotEq(in[0].Schema.Validate(1.5), nil, "should reject values out of range")
+	a.NotEq(in[1].Schema.Validate("tanh"), nil, "should reject values out of enum")
+	a.Eq(in[1].Schema.Validate("sigmoid"), nil, "should accept enum values")
+
+	d = r.Add("bad", func(rate float64) {}).Input(0, "", registry.Range(0, 1), registry.Default(2))
+	a.NotEq(d.Err, nil, "should reject defaults out of range")
+	d = r.Add("bad2", func(rate float64) {}).Input(1, "rate")
+	a.Eq(d.Err.Error(), "Invalid input: entry 'bad2' has no input 1", "should reject missing inputs")
+
+	a.Eq(e.ValidateInput(0, 0.5), nil, "should accept values in range")
+	a.Eq(e.ValidateInput(0, float32(1.5)).Error(), "Invalid input: entry 'train' input 0: value: 1.5 is greater than 1", "should reject values out of range")
+	a.NotEq(e.ValidateInput(1, "tanh"), nil, "should reject values out of enum")
+	a.Eq(e.ValidateInput(2, -1), nil, "should not check inputs without constraints")
+}
+
+func TestDescriberNotify(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	d := r.Add(strings.ToUpper)
+
+	events := []registry.Event{}
+	r.Watch(func(ev registry.Event) { events = append(events, ev) })
+	d.Description("upper").Tags("strings").Extra("color", "red").Pure()
+	a.Eq(len(events), 4, "every change should notify")
+	a.Eq(events[3].Kind, registry.EventReplace, "should notify a replace")
+
+	e, _ := r.Entry("ToUpper")
+	a.Eq(d.Entries()[0], e, "describer should hold the registered entry")
+	a.Eq(e.Pure, true, "should be pure")
+
+	r.Document("ToUpper", registry.Doc{Inputs: []string{"s"}})
+	e, _ = r.Entry("ToUpper")
+	a.Eq(len(events), 5, "document should notify")
+	a.Eq(e.Description.Inputs[0].Name, "s", "should be documented")
+}
+
+func TestDescriberClone(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add(strings.ToUpper).Tags("strings")
+
+	c := r.Clone()
+	ce, _ := c.Entry("ToUpper")
+	registry.Describer(ce).Tags("cloned").Extra("k", 1)
+
+	e, _ := r.Entry("ToUpper")
+	a.Eq(e.Description.Tags, []string{"strings"}, "original should not change")
+	a.Eq(len(e.Description.Extra), 0, "original extras should not change")
+	ce, _ = c.Entry("ToUpper")
+	a.Eq(ce.Description.Tags, []string{"cloned"}, "clone should change")
+}
+
+func TestDescriberConcurrent(t *testing.T) {
+	r := registry.New()
+	d := r.Add(strings.ToUpper, strings.ToLower)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			r.Descriptions()
+			r.Clone()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		d.Description("case").Tags("strings").Extra("i", i).Input(0, "s").Pure()
+	}
+	<-done
+}
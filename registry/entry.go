@@ -7,51 +7,80 @@ import (
 
 //DescType type Description
 type DescType struct {
-	Type string `json:"type"`
-	Name string `json:"name"`
+	Type   string  `json:"type"`
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema,omitempty"`
+	// Widget UI control hint for inputs
+	Widget string `json:"widget,omitempty"`
 }
 
 // Entry contains a function description params etc
 type Entry struct {
 	registry    *R
 	fn          interface{}
+	Params      []reflect.Type // configuration of factory entries
 	Inputs      []reflect.Type
 	Output      reflect.Type
 	Description Description
 	Err         error
+
+	// Remote entries are dispatched to workers
+	Remote bool
+	// Pure entries always return the same output for the same inputs
+	// and have no side effects, they can be merged and folded
+	Pure bool
+	// Version of the entry, registered as "name@version"
+	Version int
+}
+
+// clone copies e as an entry of r with its own description
+func (e *Entry) clone(r *R) *Entry {
+	ne := *e
+	ne.registry = r
+	ne.Description = e.Description.clone()
+	return &ne
 }
 
 // NewEntry creates and describes a New Entry
 func NewEntry(r *R, fn interface{}) (*Entry, error) {
-	e := &Entry{registry: r, fn: fn}
+	e := &Entry{registry: r, fn: fn, Version: 1}
 
 	fntyp := reflect.TypeOf(e.fn)
 	if fntyp.Kind() != reflect.Func {
 		return nil, ErrNotAFunc
 	}
+	// Factory entries build the operation func from their params
+	var Params []DescType
+	if fntyp.NumOut() > 0 && fntyp.Out(0).Kind() == reflect.Func {
+		Params = make([]DescType, fntyp.NumIn())
+		for i := range Params {
+			pTyp := fntyp.In(i)
+			Params[i] = DescType{Type: fmt.Sprint(pTyp), Schema: SchemaOf(pTyp)}
+			e.Params = append(e.Params, pTyp)
+		}
+		fntyp = fntyp.Out(0)
+	}
+
 	var Output DescType
 	if fntyp.NumOut() > 0 {
 		outTyp := fntyp.Out(0)
-		if outTyp.Kind() == reflect.Func {
-			outTyp.Out(0)
-		}
-		Output = DescType{fmt.Sprint(outTyp), ""}
+		Output = DescType{Type: fmt.Sprint(outTyp), Schema: SchemaOf(outTyp)}
 		e.Output = outTyp // ?
 
 	}
 
-	fnTyp := reflect.TypeOf(e.fn)
-	nInputs := fnTyp.NumIn()
+	nInputs := fntyp.NumIn()
 
 	Inputs := make([]DescType, nInputs)
 	for i := 0; i < nInputs; i++ {
-		inTyp := fnTyp.In(i)
-		Inputs[i] = DescType{fmt.Sprint(inTyp), ""}
+		inTyp := fntyp.In(i)
+		Inputs[i] = DescType{Type: fmt.Sprint(inTyp), Schema: SchemaOf(inTyp)}
 		e.Inputs = append(e.Inputs, inTyp) // ?
 	}
 
 	e.Description = Description{
 		Tags:   []string{"generic"},
+		Params: Params,
 		Inputs: Inputs,
 		Output: Output,
 		Extra:  map[string]interface{}{},
@@ -59,6 +88,12 @@ func NewEntry(r *R, fn interface{}) (*Entry, error) {
 	return e, nil
 }
 
+// Call the entry function with params, if the function returns more than
+// one value and the last is a non nil error it will be returned
+func (e *Entry) Call(params ...interface{}) (interface{}, error) {
+	return CallerOf(e.fn)(params...)
+}
+
 // Describer return a description builder
 func (e *Entry) Describer() *EDescriber {
 	return Describer(e)
@@ -69,3 +69,17 @@ func TestEntryBatch(t *testing.T) {
 	}
 
 }
+
+func TestEntryCall(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	e, err := registry.NewEntry(r, func(a, b int) int { return a + b })
+	a.Eq(err, nil, "should create entry")
+
+	res, err := e.Call(1, 2)
+	a.Eq(err, nil, "should call entry")
+	a.Eq(res, 3, "should return the result")
+
+	_, err = e.Call(1)
+	a.Eq(err, registry.ErrInput, "should error with wrong number of params")
+}
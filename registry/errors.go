@@ -4,7 +4,12 @@ import "errors"
 
 // flow Errors
 var (
-	ErrNotFound = errors.New("Entry not found")
-	ErrNotAFunc = errors.New("Is not a function")
-	ErrOutput   = errors.New("Invalid output")
+	ErrNotFound     = errors.New("Entry not found")
+	ErrNotAFunc     = errors.New("Is not a function")
+	ErrOutput       = errors.New("Invalid output")
+	ErrInput        = errors.New("Invalid input")
+	ErrNoDispatcher = errors.New("No dispatcher for remote entry")
+	ErrAmbiguous    = errors.New("Ambiguous entry name")
+	ErrConflict     = errors.New("Entry name conflict")
+	ErrVersion      = errors.New("Entry version not found")
 )
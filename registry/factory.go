@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Factory reports if the entry func builds the operation func, the entry
+// params configure it and the inputs are the ones of the built func, a last
+// error result fails the build
+func (e *Entry) Factory() bool {
+	typ := reflect.TypeOf(e.fn)
+	return typ.NumOut() > 0 && typ.Out(0).Kind() == reflect.Func
+}
+
+// Param describes the configuration param i of factory entries with name
+// and options, like Input
+func (d *EDescriber) Param(i int, name string, opts ...InputOption) *EDescriber {
+	return d.describeType("param", func(e *Entry) []DescType { return e.Description.Params }, i, name, opts)
+}
+
+// DefaultParam returns the default value of param i converted to the param
+// type, nil if there is no default
+func (e *Entry) DefaultParam(i int) (interface{}, error) {
+	if i < 0 || i >= len(e.Params) || i >= len(e.Description.Params) {
+		return nil, nil
+	}
+	return defaultValue(e.Description.Params[i].Schema, e.Params[i])
+}
+
+// paramValues converts and validates the params of a factory, nil or
+// missing params use the default or the zero value
+func (e *Entry) paramValues(params []interface{}) ([]reflect.Value, error) {
+	if len(params) > len(e.Params) {
+		return nil, fmt.Errorf("%v: expected %d params, got %d", ErrInput, len(e.Params), len(params))
+	}
+	ret := make([]reflect.Value, len(e.Params))
+	for i, typ := range e.Params {
+		var v interface{}
+		if i < len(params) {
+			v = params[i]
+		}
+		if v == nil {
+			def, err := e.DefaultParam(i)
+			if err != nil {
+				return nil, fmt.Errorf("%v: param %d default: %v", ErrInput, i, err)
+			}
+			v = def
+		}
+		if v == nil {
+			ret[i] = reflect.Zero(typ)
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		switch {
+		case rv.Type().AssignableTo(typ):
+		case IsNumber(rv.Kind()) && IsNumber(typ.Kind()):
+			conv, err := ConvertNumber(rv, typ)
+			if err != nil {
+				return nil, fmt.Errorf("%v: param %d: %v", ErrInput, i, err)
+			}
+			rv = conv
+		default:
+			return nil, fmt.Errorf("%v: param %d expects %v, got %T", ErrInput, i, typ, v)
+		}
+		if i < len(e.Description.Params) {
+			// opaque values can't be validated
+			if jv, err := jsonValue(rv.Interface()); err == nil {
+				if err := e.Description.Params[i].Schema.Validate(jv); err != nil {
+					return nil, fmt.Errorf("%v: param %d: %v", ErrInput, i, err)
+				}
+			}
+		}
+		ret[i] = rv
+	}
+	return ret, nil
+}
@@ -0,0 +1,136 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// InputOption sets a default, constraint or UI hint on an entry input
+type InputOption func(*DescType) error
+
+// Default value used when the input has no link nor value
+func Default(v interface{}) InputOption {
+	return func(d *DescType) error {
+		jv, err := jsonValue(v)
+		if err != nil {
+			return err
+		}
+		d.Schema.Default = jv
+		return nil
+	}
+}
+
+// Range constrains a numeric input to [min, max]
+func Range(min, max float64) InputOption {
+	return func(d *DescType) error {
+		if min > max {
+			return fmt.Errorf("invalid range [%v, %v]", min, max)
+		}
+		d.Schema.Minimum, d.Schema.Maximum = &min, &max
+		return nil
+	}
+}
+
+// Enum constrains the input to one of values, rendered as a select
+func Enum(values ...interface{}) InputOption {
+	return func(d *DescType) error {
+		enum := make([]interface{}, len(values))
+		for i, v := range values {
+			jv, err := jsonValue(v)
+			if err != nil {
+				return err
+			}
+			enum[i] = jv
+		}
+		d.Schema.Enum = enum
+		if d.Widget == "" {
+			d.Widget = "select"
+		}
+		return nil
+	}
+}
+
+// Widget hints the UI control used to edit the input, like "slider",
+// "select", "textarea" or "color"
+func Widget(name string) InputOption {
+	return func(d *DescType) error {
+		d.Widget = name
+		return nil
+	}
+}
+
+// Input describes input i with name and options, an empty name keeps the
+// current one, the default must satisfy the input constraints
+func (d *EDescriber) Input(i int, name string, opts ...InputOption) *EDescriber {
+	return d.describeType("input", func(e *Entry) []DescType { return e.Description.Inputs }, i, name, opts)
+}
+
+func (d *EDescriber) describeType(kind string, descs func(*Entry) []DescType, i int, name string, opts []InputOption) *EDescriber {
+	return d.update(func(e *Entry) {
+		list := descs(e)
+		if i < 0 || i >= len(list) {
+			d.Err = fmt.Errorf("%v: entry '%s' has no %s %d", ErrInput, e.Description.Name, kind, i)
+			return
+		}
+		in := &list[i]
+		if name != "" {
+			in.Name = name
+		}
+		if in.Schema == nil {
+			in.Schema = &Schema{}
+		}
+		for _, opt := range opts {
+			if err := opt(in); err != nil {
+				d.Err = fmt.Errorf("%v: entry '%s' %s %d: %v", ErrInput, e.Description.Name, kind, i, err)
+			}
+		}
+		if err := in.Schema.Validate(in.Schema.Default); err != nil {
+			d.Err = fmt.Errorf("%v: entry '%s' %s %d default: %v", ErrInput, e.Description.Name, kind, i, err)
+		}
+	})
+}
+
+// ValidateInput checks v against the constraints of input i, values of
+// inputs without constraints and opaque values are not checked
+func (e *Entry) ValidateInput(i int, v interface{}) error {
+	if i < 0 || i >= len(e.Description.Inputs) {
+		return nil
+	}
+	s := e.Description.Inputs[i].Schema
+	if s == nil || s.Minimum == nil && s.Maximum == nil && len(s.Enum) == 0 {
+		return nil
+	}
+	jv, err := jsonValue(v)
+	if err != nil {
+		return nil
+	}
+	if err := s.Validate(jv); err != nil {
+		return fmt.Errorf("%v: entry '%s' input %d: %v", ErrInput, e.Description.Name, i, err)
+	}
+	return nil
+}
+
+// DefaultInput returns the default value of input i converted to the input
+// type, nil if there is no default
+func (e *Entry) DefaultInput(i int) (interface{}, error) {
+	if i < 0 || i >= len(e.Inputs) || i >= len(e.Description.Inputs) {
+		return nil, nil
+	}
+	return defaultValue(e.Description.Inputs[i].Schema, e.Inputs[i])
+}
+
+func defaultValue(s *Schema, typ reflect.Type) (interface{}, error) {
+	if s == nil || s.Default == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(s.Default)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(typ)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict policy for same named entries when merging
+type Conflict int
+
+// Merge conflict policies
+const (
+	// ConflictReplace the merged entry replaces the existing one
+	ConflictReplace Conflict = iota
+	// ConflictError nothing is merged and an error is returned
+	ConflictError
+	// ConflictSkip the existing entry is kept
+	ConflictSkip
+	// ConflictPrefix the merged entry is added prefixed by the namespace of
+	// the merged registry
+	ConflictPrefix
+)
+
+// UseNamespace qualifies the names of entries added afterwards as
+// "ns.name", entries can still be fetched by name if unambiguous
+func (r *R) UseNamespace(ns string) *R {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.namespace = ns
+	return r
+}
+
+// Namespace returns the namespace of entries added to r
+func (r *R) Namespace() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namespace
+}
+
+// MergeConflict merges the entries of or, same named entries are handled by
+// the policy c
+func (r *R) MergeConflict(or *R, c Conflict) error {
+	or.mu.RLock()
+	ns := or.namespace
+	entries := make(map[string]*Entry, len(or.entries))
+	for k, v := range or.entries {
+		entries[k] = v
+	}
+	or.mu.RUnlock()
+
+	r.mu.Lock()
+	events := []Event{}
+	merge := map[string]*Entry{}
+	for name, e := range entries {
+		e = e.clone(r)
+		_, exists := r.entries[name]
+		if !exists {
+			merge[name] = e
+			events = append(events, Event{EventAdd, name, e})
+			continue
+		}
+		switch c {
+		case ConflictReplace:
+			merge[name] = e
+			events = append(events, Event{EventReplace, name, e})
+		case ConflictError:
+			r.mu.Unlock()
+			return fmt.Errorf("%w: '%s'", ErrConflict, name)
+		case ConflictSkip:
+		case ConflictPrefix:
+			if ns == "" || strings.HasPrefix(name, ns+".") {
+				r.mu.Unlock()
+				return fmt.Errorf("%w: '%s' can't be prefixed", ErrConflict, name)
+			}
+			pname := ns + "." + name
+			if _, ok := r.entries[pname]; ok {
+				r.mu.Unlock()
+				return fmt.Errorf("%w: '%s'", ErrConflict, pname)
+			}
+			e.Description.Name, _, _ = splitVersion(pname)
+			e.Description.Namespace = ns
+			merge[pname] = e
+			events = append(events, Event{EventAdd, pname, e})
+		}
+	}
+	for name, e := range merge {
+		r.put(name, e)
+	}
+	r.mu.Unlock()
+
+	for _, ev := range events {
+		r.notify(ev)
+	}
+	return nil
+}
+
+// lookup resolves name to the entry key, "name@N" matches the version N and
+// name the latest version, names without namespace match namespaced entries
+// if only one namespace has that name, r.mu must be held
+func (r *R) lookup(name string) (string, *Entry, error) {
+	base, version, err := splitVersion(name)
+	if err != nil {
+		return name, nil, err
+	}
+	if e, ok := r.entries[versionKey(base, version)]; ok && version > 0 {
+		return versionKey(base, version), e, nil
+	}
+	// exact unversioned key without newer versions
+	if e, ok := r.entries[base]; ok && version == 0 && r.versioned[base] == 0 {
+		return base, e, nil
+	}
+
+	found := map[string]string{} // base to key
+	versioned := false
+	for k, e := range r.entries {
+		kb, _, _ := splitVersion(k)
+		ns := e.Description.Namespace
+		if kb != base && (ns == "" || kb != ns+"."+base) {
+			continue
+		}
+		versioned = true
+		if version > 0 && e.Version != version {
+			continue
+		}
+		if fk, ok := found[kb]; ok && r.entries[fk].Version > e.Version {
+			continue
+		}
+		found[kb] = k
+	}
+	if k, ok := found[base]; ok {
+		return k, r.entries[k], nil
+	}
+	switch len(found) {
+	case 0:
+		if versioned {
+			return name, nil, ErrVersion
+		}
+		return name, nil, ErrNotFound
+	case 1:
+		for _, k := range found {
+			return k, r.entries[k], nil
+		}
+	}
+	return name, nil, ErrAmbiguous
+}
@@ -0,0 +1,301 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Plugin errors
+var (
+	ErrPluginTimeout = errors.New("plugin call timed out")
+)
+
+// Plugin is an external executable providing entries through a line based
+// JSON protocol on stdin/stdout, each request is answered by one response:
+//
+//	-> {"id":1,"method":"describe"}
+//	<- {"id":1,"result":[{"name":"upper","description":"","tags":["string"],
+//	     "inputs":[{"type":"string","name":"s"}],"output":{"type":"string","name":""}}]}
+//	-> {"id":2,"method":"call","entry":"upper","args":["hello"]}
+//	<- {"id":2,"result":"HELLO"}
+//	<- {"id":2,"error":"something failed"}
+//
+// Crashed plugins are restarted on the next call, calls taking longer than
+// Timeout kill the plugin process, calls are never sent twice
+type Plugin struct {
+	sync.Mutex
+	Path    string
+	Timeout time.Duration
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	nextID int
+
+	describer *EDescriber
+}
+
+type pluginRequest struct {
+	ID     int           `json:"id"`
+	Method string        `json:"method"`
+	Entry  string        `json:"entry,omitempty"`
+	Args   []interface{} `json:"args,omitempty"`
+}
+
+type pluginResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+type pluginEntry struct {
+	Name   string     `json:"name"`
+	Desc   string     `json:"description"`
+	Tags   []string   `json:"tags"`
+	Inputs []DescType `json:"inputs"`
+	Output DescType   `json:"output"`
+}
+
+// pluginTypes known type names reflected for plugin inputs and outputs,
+// anything else is treated as interface{}
+var pluginTypes = map[string]reflect.Type{
+	"string":    reflect.TypeOf(""),
+	"int":       reflect.TypeOf(0),
+	"float64":   reflect.TypeOf(0.0),
+	"bool":      reflect.TypeOf(false),
+	"[]string":  reflect.TypeOf([]string{}),
+	"[]int":     reflect.TypeOf([]int{}),
+	"[]float64": reflect.TypeOf([]float64{}),
+	"[]byte":    reflect.TypeOf([]byte{}),
+}
+
+func pluginType(name string) reflect.Type {
+	if t, ok := pluginTypes[name]; ok {
+		return t
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+// LoadPlugins loads every executable in dir as a plugin
+func (r *R) LoadPlugins(dir string, timeout time.Duration) ([]*Plugin, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*Plugin{}
+	for _, fi := range files {
+		if fi.IsDir() || fi.Mode()&0111 == 0 {
+			continue
+		}
+		p, err := r.LoadPlugin(filepath.Join(dir, fi.Name()), timeout)
+		if err != nil {
+			for _, lp := range ret {
+				lp.Close()
+			}
+			return nil, err
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// LoadPlugin starts the executable at path and registers its entries
+func (r *R) LoadPlugin(path string, timeout time.Duration) (*Plugin, error) {
+	p := &Plugin{Path: path, Timeout: timeout}
+
+	raw, err := p.request(pluginRequest{Method: "describe"})
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s: %v", path, err)
+	}
+	descs := []pluginEntry{}
+	if err := json.Unmarshal(raw, &descs); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s: %v", path, err)
+	}
+
+	p.describer = Describer()
+	for _, pd := range descs {
+		e, err := r.register(pd.Name, p.entryFunc(pd))
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		pd := pd
+		e = r.update(e, func(e *Entry) {
+			e.Inputs = make([]reflect.Type, len(pd.Inputs))
+			for i, in := range pd.Inputs {
+				e.Inputs[i] = pluginType(in.Type)
+				if in.Schema == nil {
+					pd.Inputs[i].Schema = SchemaOf(e.Inputs[i])
+				}
+			}
+			e.Output = pluginType(pd.Output.Type)
+			if pd.Output.Schema == nil {
+				pd.Output.Schema = SchemaOf(e.Output)
+			}
+			e.Description.Desc = pd.Desc
+			e.Description.Inputs = pd.Inputs
+			e.Description.Output = pd.Output
+			if len(pd.Tags) > 0 {
+				e.Description.Tags = pd.Tags
+			}
+		})
+		p.describer.entries = append(p.describer.entries, e)
+	}
+	return p, nil
+}
+
+// Describer returns the entries registered by the plugin
+func (p *Plugin) Describer() *EDescriber {
+	return p.describer
+}
+
+// Close stops the plugin process
+func (p *Plugin) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	return p.stop()
+}
+
+func (p *Plugin) entryFunc(pd pluginEntry) func(...interface{}) (interface{}, error) {
+	outType := pluginType(pd.Output.Type)
+	return func(args ...interface{}) (interface{}, error) {
+		raw, err := p.request(pluginRequest{Method: "call", Entry: pd.Name, Args: args})
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) == 0 {
+			return nil, nil
+		}
+		v := reflect.New(outType)
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+}
+
+func (p *Plugin) start() error {
+	cmd := exec.Command(p.Path)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p.cmd, p.stdin, p.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+func (p *Plugin) stop() error {
+	if p.cmd == nil {
+		return nil
+	}
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	err := p.cmd.Wait()
+	p.cmd = nil
+	return err
+}
+
+// request sends a request restarting the plugin once if it exited before
+// receiving it, requests received by a crashing plugin are not sent again
+func (p *Plugin) request(req pluginRequest) (json.RawMessage, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	var err error
+	for try := 0; try < 2; try++ {
+		if p.cmd == nil {
+			if err = p.start(); err != nil {
+				return nil, err
+			}
+		}
+		var res pluginResponse
+		var sent bool
+		res, sent, err = p.roundTrip(req)
+		if err != nil {
+			p.stop()
+		}
+		if err != nil && !sent { // exited, restart
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if res.Error != "" {
+			return nil, errors.New(res.Error)
+		}
+		return res.Result, nil
+	}
+	return nil, err
+}
+
+// roundTrip sends req and waits the response, sent is false if the request
+// didn't reach the plugin
+func (p *Plugin) roundTrip(req pluginRequest) (res pluginResponse, sent bool, err error) {
+	p.nextID++
+	req.ID = p.nextID
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return res, true, err
+	}
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return res, false, err
+	}
+
+	type result struct {
+		res pluginResponse
+		err error
+	}
+	resCh := make(chan result, 1)
+	go func(stdout *bufio.Reader) {
+		for {
+			line, err := stdout.ReadBytes('\n')
+			if err != nil {
+				resCh <- result{err: err}
+				return
+			}
+			res := pluginResponse{}
+			if err := json.Unmarshal(line, &res); err != nil {
+				resCh <- result{err: err}
+				return
+			}
+			if res.ID == req.ID { // skip stale responses
+				resCh <- result{res: res}
+				return
+			}
+		}
+	}(p.stdout)
+
+	var timeout <-chan time.Time
+	if p.Timeout > 0 {
+		t := time.NewTimer(p.Timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case r := <-resCh:
+		return r.res, true, r.err
+	case <-timeout:
+		return res, true, ErrPluginTimeout
+	}
+}
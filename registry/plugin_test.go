@@ -0,0 +1,152 @@
+package registry_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+// The test binary acts as a plugin when this env var is set
+const pluginEnv = "FLOW_REGISTRY_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) == "1" {
+		testPlugin()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func testPlugin() {
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		req := struct {
+			ID     int
+			Method string
+			Entry  string
+			Args   []json.RawMessage
+		}{}
+		json.Unmarshal(in.Bytes(), &req)
+		res := map[string]interface{}{"id": req.ID}
+		switch req.Method {
+		case "describe":
+			res["result"] = []registry.M{
+				{"name": "upper", "tags": []string{"plugin"},
+					"inputs": []registry.M{{"type": "string", "name": "s"}},
+					"output": registry.M{"type": "string"}},
+				{"name": "sleep", "inputs": []registry.M{{"type": "int", "name": "ms"}}},
+				{"name": "crash"},
+				{"name": "fail"},
+			}
+		case "call":
+			switch req.Entry {
+			case "upper":
+				var s string
+				json.Unmarshal(req.Args[0], &s)
+				res["result"] = strings.ToUpper(s)
+			case "sleep":
+				var ms int
+				json.Unmarshal(req.Args[0], &ms)
+				time.Sleep(time.Duration(ms) * time.Millisecond)
+			case "crash":
+				if log := os.Getenv("FLOW_PLUGIN_LOG"); log != "" {
+					f, _ := os.OpenFile(log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+					fmt.Fprintln(f, "crash")
+					f.Close()
+				}
+				os.Exit(1)
+			default:
+				res["error"] = fmt.Sprintf("failed %s", req.Entry)
+			}
+		}
+		out.Encode(res)
+	}
+}
+
+func pluginDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s=1 exec %q\n", pluginEnv, bin)
+	err = ioutil.WriteFile(filepath.Join(dir, "testplugin"), []byte(script), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// not executable, should be ignored
+	ioutil.WriteFile(filepath.Join(dir, "README"), []byte("readme"), 0644)
+	return dir
+}
+
+func TestLoadPlugins(t *testing.T) {
+	a := assert.A(t)
+	dir := pluginDir(t)
+	defer os.RemoveAll(dir)
+
+	r := registry.New()
+	plugins, err := r.LoadPlugins(dir, time.Second)
+	a.Eq(err, nil, "should load plugins")
+	a.Eq(len(plugins), 1, "should load one plugin")
+	defer plugins[0].Close()
+	a.Eq(len(plugins[0].Describer().Entries()), 4, "should register plugin entries")
+
+	e, err := r.Entry("upper")
+	a.Eq(err, nil, "entry should be registered")
+	a.Eq(e.Description.Inputs[0].Name, "s", "should have input description")
+	a.Eq(e.Description.Tags, []string{"plugin"}, "should have plugin tags")
+
+	res, err := e.Call("hello")
+	a.Eq(err, nil, "call should not error")
+	a.Eq(res, "HELLO", "should call the plugin")
+
+	e, _ = r.Entry("fail")
+	_, err = e.Call()
+	a.Eq(fmt.Sprint(err), "failed fail", "should return plugin error")
+}
+
+func TestPluginRestart(t *testing.T) {
+	a := assert.A(t)
+	dir := pluginDir(t)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("FLOW_PLUGIN_LOG", filepath.Join(dir, "calls.log"))
+	defer os.Unsetenv("FLOW_PLUGIN_LOG")
+
+	r := registry.New()
+	p, err := r.LoadPlugin(filepath.Join(dir, "testplugin"), 200*time.Millisecond)
+	a.Eq(err, nil, "should load plugin")
+	defer p.Close()
+
+	crash, _ := r.Entry("crash")
+	_, err = crash.Call()
+	a.NotEq(err, nil, "crashing call should error")
+	calls, _ := ioutil.ReadFile(filepath.Join(dir, "calls.log"))
+	a.Eq(string(calls), "crash\n", "crashing call should not be sent twice")
+
+	upper, _ := r.Entry("upper")
+	res, err := upper.Call("a")
+	a.Eq(err, nil, "plugin should be restarted after crash")
+	a.Eq(res, "A", "should call the restarted plugin")
+
+	sleep, _ := r.Entry("sleep")
+	_, err = sleep.Call(1000)
+	a.Eq(err, registry.ErrPluginTimeout, "should timeout")
+
+	res, err = upper.Call("b")
+	a.Eq(err, nil, "plugin should be restarted after timeout")
+	a.Eq(res, "B", "should call the restarted plugin")
+}
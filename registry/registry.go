@@ -5,6 +5,7 @@ import (
 	"path"
 	"reflect"
 	"runtime"
+	"sync"
 )
 
 // Global
@@ -18,34 +19,218 @@ var (
 // M Alias for map[string]interface{}
 type M = map[string]interface{}
 
-// R the function registry
+// Dispatcher executes calls of remote entries
+type Dispatcher interface {
+	Call(name string, e *Entry, params ...interface{}) (interface{}, error)
+}
+
+// Event kinds
+const (
+	EventAdd     = "add"
+	EventReplace = "replace"
+	EventRemove  = "remove"
+)
+
+// Event registry change notification
+type Event struct {
+	Kind  string
+	Name  string
+	Entry *Entry // nil when removed
+}
+
+// R the function registry, safe for concurrent use
 type R struct {
-	entries map[string]*Entry
+	mu         sync.RWMutex
+	entries    map[string]*Entry
+	versioned  map[string]int // number of "name@N" keys of each name
+	dispatcher Dispatcher
+	namespace  string
+
+	watchMu  sync.Mutex
+	watchers map[int]func(Event)
+	watchID  int
 }
 
 // New creates a new registry
 func New() *R {
-	r := &R{map[string]*Entry{}}
+	r := &R{
+		entries:   map[string]*Entry{},
+		versioned: map[string]int{},
+		watchers:  map[int]func(Event){},
+	}
 	// create a base function here?
 	return r
 }
 
-// Clone an existing registry
+// UseDispatcher sets the dispatcher for remote entries
+func (r *R) UseDispatcher(d Dispatcher) *R {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatcher = d
+	return r
+}
+
+// Clone an existing registry, watchers are not cloned
 func (r *R) Clone() *R {
-	newR := &R{map[string]*Entry{}}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	newR := New()
+	newR.dispatcher = r.dispatcher
+	newR.namespace = r.namespace
 	for k, v := range r.entries {
-		newR.entries[k] = v
+		newR.put(k, v.clone(newR))
 	}
 	return newR
 }
 
-// Merge other registry
+// Merge other registry, same named entries are replaced
 func (r *R) Merge(or *R) {
-	for k, v := range or.entries {
-		r.entries[k] = v
+	r.MergeConflict(or, ConflictReplace)
+}
+
+// Remove the entry name
+func (r *R) Remove(name string) error {
+	r.mu.Lock()
+	name, _, err := r.lookup(name)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.del(name)
+	r.mu.Unlock()
+
+	r.notify(Event{EventRemove, name, nil})
+	return nil
+}
+
+// Replace the func of the existing entry name, the description, tags,
+// extras and flags of the previous entry are kept
+func (r *R) Replace(name string, fn interface{}) *EDescriber {
+	d := &EDescriber{[]*Entry{}, nil}
+	e, err := NewEntry(r, fn)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	r.mu.Lock()
+	name, old, err := r.lookup(name)
+	if err != nil {
+		r.mu.Unlock()
+		d.Err = err
+		return d
+	}
+	e.Description.Name = old.Description.Name
+	e.Description.Namespace = old.Description.Namespace
+	e.Version = old.Version
+	e.Description.Desc = old.Description.Desc
+	e.Description.Tags = old.Description.Tags
+	e.Description.Extra = old.Description.Extra
+	e.Remote = old.Remote
+	e.Pure = old.Pure
+	r.entries[name] = e
+	r.mu.Unlock()
+
+	r.notify(Event{EventReplace, name, e})
+	d.entries = append(d.entries, e)
+	return d
+}
+
+// Watch calls fn on every change of the registry, the returned func stops
+// watching
+func (r *R) Watch(fn func(Event)) func() {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	r.watchID++
+	id := r.watchID
+	r.watchers[id] = fn
+	return func() {
+		r.watchMu.Lock()
+		defer r.watchMu.Unlock()
+		delete(r.watchers, id)
+	}
+}
+
+func (r *R) notify(ev Event) {
+	r.watchMu.Lock()
+	watchers := make([]func(Event), 0, len(r.watchers))
+	for _, fn := range r.watchers {
+		watchers = append(watchers, fn)
+	}
+	r.watchMu.Unlock()
+	for _, fn := range watchers {
+		fn(ev)
 	}
 }
 
+// update replaces the registered entry e by a copy changed by fn and
+// notifies watchers, entries are not changed in place once registered so
+// readers holding them don't race with describers. Entries not registered
+// in r are changed in place
+func (r *R) update(e *Entry, fn func(*Entry)) *Entry {
+	r.mu.Lock()
+	key := r.keyOf(e)
+	if key == "" {
+		r.mu.Unlock()
+		fn(e)
+		return e
+	}
+	ne := e.clone(r)
+	fn(ne)
+	r.entries[key] = ne
+	r.mu.Unlock()
+
+	r.notify(Event{EventReplace, key, ne})
+	return ne
+}
+
+// keyOf returns the key of the registered entry e, r.mu must be held
+func (r *R) keyOf(e *Entry) string {
+	for k, re := range r.entries {
+		if re == e {
+			return k
+		}
+	}
+	return ""
+}
+
+// put stores e at key, r.mu must be held
+func (r *R) put(key string, e *Entry) {
+	if _, exists := r.entries[key]; !exists {
+		if base, v, _ := splitVersion(key); v > 0 {
+			r.versioned[base]++
+		}
+	}
+	r.entries[key] = e
+}
+
+// del removes the entry at key, r.mu must be held
+func (r *R) del(key string) {
+	if _, exists := r.entries[key]; !exists {
+		return
+	}
+	if base, v, _ := splitVersion(key); v > 0 {
+		r.versioned[base]--
+		if r.versioned[base] == 0 {
+			delete(r.versioned, base)
+		}
+	}
+	delete(r.entries, key)
+}
+
+// set stores the entry and notifies watchers
+func (r *R) set(name string, e *Entry) {
+	r.mu.Lock()
+	_, exists := r.entries[name]
+	r.put(name, e)
+	r.mu.Unlock()
+
+	kind := EventAdd
+	if exists {
+		kind = EventReplace
+	}
+	r.notify(Event{kind, name, e})
+}
+
 // Add function to registry
 func (r *R) Add(args ...interface{}) *EDescriber {
 
@@ -100,51 +285,91 @@ func (r *R) register(name string, v interface{}) (*Entry, error) {
 	if err != nil {
 		return nil, err
 	}
-	r.entries[name] = e
+	base, version, err := splitVersion(name)
+	if err != nil {
+		return nil, err
+	}
+	if version > 0 {
+		e.Version = version
+	}
+	r.mu.RLock()
+	ns := r.namespace
+	r.mu.RUnlock()
+	if ns != "" {
+		base = ns + "." + base
+		e.Description.Namespace = ns
+	}
+	e.Description.Name = base
+	r.set(versionKey(base, e.Version), e)
 	return e, nil
 }
 
-// Get an entry
+// Get the func of entry name, factory entries are called with params to
+// build it, missing params use their defaults
 func (r *R) Get(name string, params ...interface{}) (interface{}, error) {
-	e, ok := r.entries[name]
-	if !ok {
-		return nil, fmt.Errorf("Entry not found '%s'", name)
+	r.mu.RLock()
+	name, e, err := r.lookup(name)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("%v '%s'", err, name)
 	}
-	v := e.fn
-	// We already know this is a function
-	// and that returns 1 or more values
-
-	vtyp := reflect.TypeOf(v)
-	if vtyp.NumOut() == 0 || vtyp.Out(0).Kind() != reflect.Func {
-		return v, nil
+	if e.Remote {
+		return r.remoteFunc(name, e), nil
 	}
-	// Constructor
-	fparam := make([]reflect.Value, len(params))
-	for i := range params {
-		fparam[i] = reflect.ValueOf(params[i])
+	if !e.Factory() {
+		return e.fn, nil
+	}
+	// Factory, build the operation func from params
+	fparam, err := e.paramValues(params)
+	if err != nil {
+		return nil, fmt.Errorf("%v '%s'", err, name)
 	}
-	// Call the func and return the thing
-	v = reflect.ValueOf(v).Call(fparam)[0].Interface()
+	res := reflect.ValueOf(e.fn).Call(fparam)
+	// factories can fail building the func with a last error result
+	if last := res[len(res)-1]; len(res) > 1 && last.Type() == errorType && !last.IsNil() {
+		return nil, fmt.Errorf("'%s': %w", name, last.Interface().(error))
+	}
+	return res[0].Interface(), nil
+}
 
-	return v, nil
+// remoteFunc returns a func that forwards calls to the dispatcher
+func (r *R) remoteFunc(name string, e *Entry) func(...interface{}) (interface{}, error) {
+	return func(params ...interface{}) (interface{}, error) {
+		r.mu.RLock()
+		d := r.dispatcher
+		r.mu.RUnlock()
+		if d == nil {
+			return nil, ErrNoDispatcher
+		}
+		return d.Call(name, e, params...)
+	}
 }
 
-// Entry fetches entries from the register
+// Entry fetches entries from the register, namespaced entries can be
+// fetched by their short name if unambiguous
 func (r *R) Entry(name string) (*Entry, error) {
-	e, ok := r.entries[name]
-	if !ok {
-		return nil, ErrNotFound
-	}
-	return e, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, e, err := r.lookup(name)
+	return e, err
 }
 
 //Describe named fn
 
-// Descriptions Description list
+// Descriptions Description list, only the latest version of each entry is
+// described
 func (r *R) Descriptions() (map[string]Description, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	ret := map[string]Description{}
 	for k, e := range r.entries {
-		ret[k] = e.Description
+		base, _, _ := splitVersion(k)
+		if d, ok := ret[base]; ok && d.Version > e.Version {
+			continue
+		}
+		d := e.Description
+		d.Version = e.Version
+		ret[base] = d
 	}
 	return ret, nil
 }
@@ -1,6 +1,7 @@
 package registry_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -217,3 +218,256 @@ func dummy1([]float32, []float32) []float32 {
 func dummy2([]float32) string {
 	return ""
 }
+
+func TestRemoteNoDispatcher(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("func", func() int { return 0 }).Remote()
+
+	ifn, err := r.Get("func")
+	a.Eq(err, nil, "should get remote func")
+
+	fn, ok := ifn.(func(...interface{}) (interface{}, error))
+	a.Eq(ok, true, "remote func should be a generic func")
+
+	_, err = fn()
+	a.Eq(err, registry.ErrNoDispatcher, "should error without dispatcher")
+}
+
+func TestRemoveReplace(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("fn", func(a int) int { return a }).Description("identity").Tags("math").Pure()
+
+	d := r.Replace("fn", func(a int) int { return a * 2 })
+	a.Eq(d.Err, nil, "should replace")
+	e, _ := r.Entry("fn")
+	res, _ := e.Call(2)
+	a.Eq(res, 4, "should call the new func")
+	a.Eq(e.Description.Desc, "identity", "should keep the description")
+	a.Eq(e.Description.Tags, []string{"math"}, "should keep the tags")
+	a.Eq(e.Pure, true, "should keep flags")
+
+	a.Eq(r.Replace("bogus", func() {}).Err, registry.ErrNotFound, "should not replace missing entries")
+
+	a.Eq(r.Remove("fn"), nil, "should remove")
+	_, err := r.Entry("fn")
+	a.Eq(err, registry.ErrNotFound, "should be removed")
+	a.Eq(r.Remove("fn"), registry.ErrNotFound, "should not remove twice")
+}
+
+func TestWatch(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	events := []string{}
+	stop := r.Watch(func(ev registry.Event) {
+		events = append(events, ev.Kind+" "+ev.Name)
+	})
+
+	r.Add("fn", func() {})
+	r.Add("fn", func() int { return 1 })
+	r.Replace("fn", func() int { return 2 })
+	other := registry.New()
+	other.Add("other", func() {})
+	r.Merge(other)
+	r.Remove("fn")
+	stop()
+	r.Add("ignored", func() {})
+
+	a.Eq(events, []string{
+		"add fn",
+		"replace fn",
+		"replace fn",
+		"add other",
+		"remove fn",
+	}, "should notify changes")
+}
+
+func TestConcurrentRegistry(t *testing.T) {
+	r := registry.New()
+	r.Watch(func(registry.Event) {})
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for j := 0; j < 100; j++ {
+				r.Add("fn", func() {})
+				r.Clone().Descriptions()
+				r.Entry("fn")
+				r.Remove("fn")
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	a := assert.A(t)
+	str := registry.New().UseNamespace("strings")
+	str.Add(strings.Split, strings.Join)
+	other := registry.New().UseNamespace("other")
+	other.Add("Join", func(a, b string) string { return a + b })
+	other.Add("Cat", func(a, b string) string { return a + b })
+
+	r := registry.New()
+	a.Eq(r.MergeConflict(str, registry.ConflictError), nil, "should merge")
+	a.Eq(r.MergeConflict(other, registry.ConflictError), nil, "namespaced entries should not conflict")
+
+	e, err := r.Entry("strings.Join")
+	a.Eq(err, nil, "should fetch by full name")
+	a.Eq(e.Description.Namespace, "strings", "should describe the namespace")
+	_, err = r.Entry("Split")
+	a.Eq(err, nil, "should fetch by unambiguous short name")
+	_, err = r.Entry("Join")
+	a.Eq(err, registry.ErrAmbiguous, "should not fetch ambiguous short name")
+	_, err = r.Get("Cat")
+	a.Eq(err, nil, "should get by short name")
+
+	desc, _ := r.Descriptions()
+	_, ok := desc["other.Cat"]
+	a.Eq(ok, true, "descriptions should be keyed by full name")
+}
+
+func TestMergeConflict(t *testing.T) {
+	a := assert.A(t)
+	base := func() *registry.R {
+		r := registry.New()
+		r.Add("fn", func() int { return 1 })
+		return r
+	}
+	other := registry.New()
+	other.Add("fn", func() int { return 2 })
+	other.Add("new", func() int { return 3 })
+
+	call := func(r *registry.R, name string) interface{} {
+		e, err := r.Entry(name)
+		if err != nil {
+			return err
+		}
+		v, _ := e.Call()
+		return v
+	}
+
+	r := base()
+	a.Eq(r.MergeConflict(other, registry.ConflictReplace), nil, "should replace")
+	a.Eq(call(r, "fn"), 2, "should use the merged entry")
+
+	r = base()
+	err := r.MergeConflict(other, registry.ConflictError)
+	a.Eq(errors.Is(err, registry.ErrConflict), true, "should error")
+	a.Eq(call(r, "new"), registry.ErrNotFound, "should not merge anything on error")
+
+	r = base()
+	a.Eq(r.MergeConflict(other, registry.ConflictSkip), nil, "should skip")
+	a.Eq(call(r, "fn"), 1, "should keep the existing entry")
+	a.Eq(call(r, "new"), 3, "should merge other entries")
+
+	r = base()
+	err = r.MergeConflict(other, registry.ConflictPrefix)
+	a.Eq(errors.Is(err, registry.ErrConflict), true, "should not prefix without namespace")
+	other.UseNamespace("other")
+	a.Eq(r.MergeConflict(other, registry.ConflictPrefix), nil, "should prefix")
+	a.Eq(call(r, "fn"), 1, "should keep the existing entry")
+	a.Eq(call(r, "other.fn"), 2, "should prefix the merged entry")
+}
+
+func TestVersion(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New().UseNamespace("ml")
+	r.Add("matMul", func(a, b int) int { return a * b })
+	r.Add("matMul@3", func(a, b, c int) int { return a * b * c })
+	r.Add("scale", func(a int) int { return a * 2 }).Version(2)
+
+	call := func(name string, params ...interface{}) interface{} {
+		e, err := r.Entry(name)
+		if err != nil {
+			return err
+		}
+		v, _ := e.Call(params...)
+		return v
+	}
+	a.Eq(call("matMul", 2, 3, 4), 24, "should fetch the latest version")
+	a.Eq(call("matMul@1", 2, 3), 6, "should fetch the exact version")
+	a.Eq(call("ml.matMul@3", 2, 3, 4), 24, "should fetch the namespaced version")
+	a.Eq(call("matMul@2"), registry.ErrVersion, "should not fetch missing versions")
+	a.Eq(call("matMul@x"), registry.ErrVersion, "should not fetch invalid versions")
+	a.Eq(call("scale@2", 2), 4, "should set version with the describer")
+	a.Eq(r.Versions("matMul"), []int{1, 3}, "should list versions")
+
+	desc, _ := r.Descriptions()
+	a.Eq(len(desc), 2, "should describe latest versions only")
+	a.Eq(desc["ml.matMul"].Version, 3, "should describe the version")
+	a.Eq(len(desc["ml.matMul"].Inputs), 3, "should describe the latest inputs")
+
+	a.Eq(r.Remove("matMul"), nil, "should remove the latest")
+	a.Eq(call("matMul", 2, 3), 6, "should fall back to previous version")
+
+	u := registry.New()
+	u.Add("neg", func(a int) int { return -a })
+	a.Eq(u.Versions("neg"), []int{1}, "should fetch the exact name")
+	u.Add("neg", func(a int) int { return -a }).Version(2)
+	a.Eq(u.Versions("neg"), []int{2}, "should move the entry to the new version")
+	e, err := u.Entry("neg")
+	a.Eq(err, nil, "should fetch the moved entry")
+	a.Eq(e.Version, 2, "should fetch the latest version")
+	u.Add("neg", func(a int) int { return -a })
+	e, _ = u.Entry("neg")
+	a.Eq(e.Version, 2, "exact name should not hide newer versions")
+	u.Remove("neg@2")
+	e, _ = u.Entry("neg")
+	a.Eq(e.Version, 1, "should fetch the exact name once newer versions are removed")
+}
+
+func TestFactoryEntry(t *testing.T) {
+	a := assert.A(t)
+
+	r := registry.New()
+	d := r.Add("prefix", func(p string, n int) func(string) string {
+		return func(s string) string { return strings.Repeat(p, n) + s }
+	}).Params("prefix").Param(1, "count", registry.Default(2))
+	a.Eq(d.Err, nil, "should describe params")
+
+	e, _ := r.Entry("prefix")
+	a.Eq(e.Factory(), true, "should be a factory")
+	a.Eq(len(e.Description.Params), 2, "should describe the params")
+	a.Eq(e.Description.Params[0].Name, "prefix", "should name params")
+	a.Eq(e.Description.Inputs[0].Type, "string", "inputs should be the built func inputs")
+	a.Eq(e.Description.Output.Type, "string", "output should be the built func output")
+
+	fn, err := r.Get("prefix", ">")
+	a.Eq(err, nil, "should build with missing params")
+	a.Eq(fn.(func(string) string)("a"), ">>a", "should use the default param")
+
+	fn, err = r.Get("prefix", "-", int64(1))
+	a.Eq(err, nil, "should convert numeric params")
+	a.Eq(fn.(func(string) string)("a"), "-a", "should use params")
+
+	_, err = r.Get("prefix", 1)
+	a.NotEq(err, nil, "should reject params of the wrong type")
+	_, err = r.Get("prefix", "-", 1, 2)
+	a.NotEq(err, nil, "should reject extra params")
+	_, err = r.Get("prefix", "-", 2.7)
+	a.NotEq(err, nil, "should not truncate params")
+	_, err = r.Get("prefix", "-", uint64(1<<63))
+	a.NotEq(err, nil, "should not overflow params")
+
+	r.Add("window", func(n int) (func([]float64) []float64, error) {
+		if n == 0 {
+			return nil, errors.New("empty window")
+		}
+		return func(v []float64) []float64 { return v[:n] }, nil
+	}).Param(0, "size", registry.Range(0, 10))
+	_, err = r.Get("window", 0)
+	a.Eq(err.Error(), "'window': empty window", "should return the factory error")
+	fn, err = r.Get("window", 2.0)
+	a.Eq(err, nil, "should build with integral params")
+	a.Eq(fn.(func([]float64) []float64)([]float64{1, 2, 3}), []float64{1, 2}, "should use params")
+	_, err = r.Get("window", 10.5)
+	a.NotEq(err, nil, "should not truncate params into range")
+
+	e, _ = r.Entry("prefix")
+	a.Eq(r.Document("prefix", registry.Doc{Params: []string{"p", "n"}}), nil, "should document")
+	a.Eq(e.Description.Params[1].Name, "count", "should keep param names")
+}
@@ -0,0 +1,263 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema JSON Schema describing values of an entry input or output, an
+// empty schema accepts any value
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+}
+
+var schemas sync.Map // reflect.Type to *Schema
+
+func init() {
+	RegisterSchema(reflect.TypeOf(time.Time{}), &Schema{Type: "string", Format: "date-time"})
+	RegisterSchema(reflect.TypeOf(time.Duration(0)), &Schema{Type: "integer", Description: "nanoseconds"})
+}
+
+// RegisterSchema overrides the schema derived for typ, used for opaque types
+// like interfaces or types with custom JSON encoding
+func RegisterSchema(typ reflect.Type, s *Schema) {
+	schemas.Store(typ, s)
+}
+
+// SchemaOf derives the JSON Schema of values of typ as encoded by
+// encoding/json, a nil type accepts any value
+func SchemaOf(typ reflect.Type) *Schema {
+	return schemaOf(typ, map[reflect.Type]bool{})
+}
+
+func schemaOf(typ reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	if typ == nil {
+		return &Schema{}
+	}
+	if s, ok := schemas.Load(typ); ok {
+		c := *s.(*Schema)
+		return &c
+	}
+	// recursive types are described once
+	if visiting[typ] {
+		return &Schema{}
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intSchema(typ.Bits(), true)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return intSchema(typ.Bits(), false)
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Ptr:
+		return schemaOf(typ.Elem(), visiting)
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(typ.Elem(), visiting)}
+	case reflect.Array:
+		n := typ.Len()
+		return &Schema{Type: "array", Items: schemaOf(typ.Elem(), visiting), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(typ.Elem(), visiting)}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		structProperties(s, typ, visiting)
+		return s
+	}
+	// interfaces, funcs and channels are opaque
+	return &Schema{}
+}
+
+func intSchema(bits int, signed bool) *Schema {
+	s := &Schema{Type: "integer"}
+	if !signed {
+		min := 0.0
+		s.Minimum = &min
+	}
+	if bits < 64 {
+		var min, max float64
+		if signed {
+			min, max = -math.Pow(2, float64(bits-1)), math.Pow(2, float64(bits-1))-1
+			s.Minimum = &min
+		} else {
+			max = math.Pow(2, float64(bits)) - 1
+		}
+		s.Maximum = &max
+	}
+	return s
+}
+
+// structProperties adds the fields of typ encoded by encoding/json to s
+func structProperties(s *Schema, typ reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		if i := strings.Index(tag, ","); i >= 0 {
+			name = tag[:i]
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			structProperties(s, ft, visiting)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = schemaOf(f.Type, visiting)
+	}
+}
+
+// Validate checks a JSON decoded value against the schema
+func (s *Schema) Validate(v interface{}) error {
+	return s.validate("value", v)
+}
+
+// ValidateJSON checks raw JSON against the schema
+func (s *Schema) ValidateJSON(raw []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+func (s *Schema) validate(path string, v interface{}) error {
+	if s == nil || v == nil {
+		return nil
+	}
+	if len(s.Enum) > 0 && !s.inEnum(v) {
+		return fmt.Errorf("%s: %v is not one of %v", path, v, s.Enum)
+	}
+	switch s.Type {
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return schemaErr(path, "boolean", v)
+		}
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			return schemaErr(path, s.Type, v)
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("%s: expected integer, got %v", path, n)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: %v is less than %v", path, n, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: %v is greater than %v", path, n, *s.Maximum)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return schemaErr(path, "string", v)
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return schemaErr(path, "array", v)
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			return fmt.Errorf("%s: expected at least %d items, got %d", path, *s.MinItems, len(arr))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			return fmt.Errorf("%s: expected at most %d items, got %d", path, *s.MaxItems, len(arr))
+		}
+		for i, item := range arr {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return schemaErr(path, "object", v)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing property '%s'", path, name)
+			}
+		}
+		for k, pv := range obj {
+			ps, ok := s.Properties[k]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+			if err := ps.validate(path+"."+k, pv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) inEnum(v interface{}) bool {
+	for _, e := range s.Enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue converts v to its JSON decoded form, the form values are
+// validated in
+func jsonValue(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var ret interface{}
+	err = json.Unmarshal(raw, &ret)
+	return ret, err
+}
+
+func schemaErr(path, expected string, v interface{}) error {
+	got := "object"
+	switch v.(type) {
+	case bool:
+		got = "boolean"
+	case float64:
+		got = "number"
+	case string:
+		got = "string"
+	case []interface{}:
+		got = "array"
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, expected, got)
+}
@@ -0,0 +1,78 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+type schemaPoint struct {
+	X, Y  float64
+	Label string    `json:"label,omitempty"`
+	Skip  string    `json:"-"`
+	When  time.Time `json:"when"`
+	hide  int
+}
+
+type schemaNode struct {
+	Value int
+	Next  *schemaNode
+}
+
+func TestSchemaOf(t *testing.T) {
+	a := assert.A(t)
+	js := func(v interface{}) string {
+		s, _ := json.Marshal(registry.SchemaOf(reflect.TypeOf(v)))
+		return string(s)
+	}
+	a.Eq(js(1.0), `{"type":"number"}`, "float schema")
+	a.Eq(js(uint8(1)), `{"type":"integer","minimum":0,"maximum":255}`, "uint8 schema")
+	a.Eq(js([]byte{}), `{"type":"string","format":"byte"}`, "bytes schema")
+	a.Eq(js([2]string{}), `{"type":"array","items":{"type":"string"},"minItems":2,"maxItems":2}`, "array schema")
+	a.Eq(js(map[string][]bool{}), `{"type":"object","additionalProperties":{"type":"array","items":{"type":"boolean"}}}`, "map schema")
+	a.Eq(js(schemaPoint{}), `{"type":"object","properties":{"X":{"type":"number"},"Y":{"type":"number"},"label":{"type":"string"},"when":{"type":"string","format":"date-time"}}}`, "struct schema")
+	a.Eq(js(schemaNode{}), `{"type":"object","properties":{"Next":{},"Value":{"type":"integer"}}}`, "recursive schema")
+	a.Eq(js(func() {}), `{}`, "opaque schema")
+
+	type opaque interface{ Opaque() }
+	typ := reflect.TypeOf((*opaque)(nil)).Elem()
+	registry.RegisterSchema(typ, &registry.Schema{Type: "string"})
+	a.Eq(registry.SchemaOf(typ).Type, "string", "should use the override")
+
+	r := registry.New()
+	r.Add("fn", func(p schemaPoint) []float64 { return nil })
+	e, _ := r.Entry("fn")
+	a.Eq(e.Description.Inputs[0].Schema.Type, "object", "should describe input schema")
+	a.Eq(e.Description.Output.Schema.Items.Type, "number", "should describe output schema")
+}
+
+func TestSchemaValidate(t *testing.T) {
+	a := assert.A(t)
+	s := registry.SchemaOf(reflect.TypeOf(map[string][]schemaPoint{}))
+	a.Eq(s.ValidateJSON([]byte(`{"a": [{"X": 1, "label": "p"}]}`)), nil, "should validate")
+
+	tests := []struct {
+		raw string
+		err string
+	}{
+		{`[]`, "value: expected object, got array"},
+		{`{"a": [{"X": "1"}]}`, "value.a[0].X: expected number, got string"},
+		{`{"a": [1]}`, "value.a[0]: expected object, got number"},
+	}
+	for _, tt := range tests {
+		err := s.ValidateJSON([]byte(tt.raw))
+		a.NotEq(err, nil, "should fail: "+tt.raw)
+		if err != nil {
+			a.Eq(err.Error(), tt.err, "error path: "+tt.raw)
+		}
+	}
+
+	u := registry.SchemaOf(reflect.TypeOf(uint8(0)))
+	a.NotEq(u.Validate(256.0), nil, "should check maximum")
+	a.NotEq(u.Validate(1.5), nil, "should check integers")
+	a.Eq(u.Validate(nil), nil, "should accept null")
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version sets the version of the entries, an entry with the same name and
+// version is replaced
+func (d *EDescriber) Version(v int) *EDescriber {
+	for i, e := range d.entries {
+		if e.registry == nil {
+			e.Version = v
+			continue
+		}
+		d.entries[i] = e.registry.setVersion(e, v)
+	}
+	return d
+}
+
+// Versions returns the versions registered for name, newest last
+func (r *R) Versions(name string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ret := []int{}
+	key, _, err := r.lookup(name)
+	if err != nil {
+		return ret
+	}
+	base, _, _ := splitVersion(key)
+	for k, e := range r.entries {
+		if kb, _, _ := splitVersion(k); kb == base {
+			ret = append(ret, e.Version)
+		}
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// setVersion moves a copy of e to the key of version v, entries not
+// registered in r are changed in place
+func (r *R) setVersion(e *Entry, v int) *Entry {
+	r.mu.Lock()
+	key := r.keyOf(e)
+	if key == "" {
+		r.mu.Unlock()
+		e.Version = v
+		return e
+	}
+	ne := e.clone(r)
+	ne.Version = v
+	base, _, _ := splitVersion(key)
+	newKey := versionKey(base, v)
+	r.del(key)
+	_, exists := r.entries[newKey]
+	r.put(newKey, ne)
+	r.mu.Unlock()
+
+	r.notify(Event{EventRemove, key, nil})
+	kind := EventAdd
+	if exists {
+		kind = EventReplace
+	}
+	r.notify(Event{kind, newKey, ne})
+	return ne
+}
+
+// splitVersion splits "name@N" in name and N, version is 0 if not set
+func splitVersion(name string) (string, int, error) {
+	i := strings.LastIndex(name, "@")
+	if i < 0 {
+		return name, 0, nil
+	}
+	v, err := strconv.Atoi(name[i+1:])
+	if err != nil || v < 1 {
+		return name, 0, ErrVersion
+	}
+	return name[:i], v, nil
+}
+
+// versionKey entry key of base at version v, version 1 is the plain name
+func versionKey(base string, v int) string {
+	if v <= 1 {
+		return base
+	}
+	return base + "@" + strconv.Itoa(v)
+}
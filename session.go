@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"time"
 )
 
 // Session operation session
@@ -13,14 +14,44 @@ type Session struct {
 	*sync.Map
 	flow    *Flow
 	ginputs []Data
+	named   map[string]Data
+	trace   *trace    // set on the run scoped copy made by startTrace
+	inputs  *sync.Map // processed inputs for the recorder
+	ready   *sync.Map // time the inputs of an operation were resolved
+	locks   *sync.Map // per operation locks
+
+	// quiet sessions don't trigger hooks, metrics or records, used by
+	// Optimize to fold constants
+	quiet bool
+
+	// batch session running input independent operations
+	shared    *Session
+	sharedOps map[*operation]bool
+}
+
+// cachedResult operation result for an operation version
+type cachedResult struct {
+	version int
+	res     Data
 }
 
 // NewSession creates a running context
 func (f *Flow) NewSession() *Session {
 	return &Session{
-		Map:  &sync.Map{},
-		flow: f,
+		Map:    &sync.Map{},
+		flow:   f,
+		inputs: &sync.Map{},
+		ready:  &sync.Map{},
+		locks:  &sync.Map{},
+	}
+}
+
+// hooks of the flow, nil for quiet sessions
+func (s *Session) hooks() *Hooks {
+	if s.quiet {
+		return nil
 	}
+	return &s.flow.hooks
 }
 
 // Inputs sets the global graph inputs
@@ -28,24 +59,41 @@ func (s *Session) Inputs(ginputs ...Data) {
 	s.ginputs = ginputs
 }
 
+// NamedInputs sets the named graph inputs
+func (s *Session) NamedInputs(inputs map[string]Data) {
+	s.named = inputs
+}
+
 // Run session run
 func (s *Session) Run(ops ...Operation) ([]Data, error) {
 	oplist := make([]*operation, len(ops))
 	for i, op := range ops {
 		oplist[i] = op.(*operation)
 	}
+	s.flow.mu.RLock()
+	defer s.flow.mu.RUnlock()
+	rs, export := s.startTrace()
+	defer export()
 
-	return s.goRunList(oplist, s.ginputs...)
+	return rs.goRunList(oplist, s.ginputs...)
 }
 
 // The main run function?
 // run runs the operation
 func (s *Session) run(op *operation, ginputs ...Data) (Data, error) {
-	op.Lock()
-	defer op.Unlock()
-	// Load from cache if any
-	if v, ok := s.Load(op); ok {
-		return v, nil
+	if s.sharedOps[op] {
+		return s.shared.run(op)
+	}
+	// operation state is per session, a flow can run in many sessions at once
+	mu, _ := s.locks.LoadOrStore(op, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	// Load from cache if any, results from edited operations are discarded
+	if v, ok := s.Load(op); ok && v.(cachedResult).version == op.version {
+		if !s.quiet {
+			opCacheHits.Inc(op.metricLabels()...)
+		}
+		return v.(cachedResult).res, nil
 	}
 
 	res, err := s.triggerRun(op, ginputs...)
@@ -53,7 +101,7 @@ func (s *Session) run(op *operation, ginputs ...Data) (Data, error) {
 		return nil, err
 	}
 
-	s.Store(op, res)
+	s.Store(op, cachedResult{op.version, res})
 	return res, nil
 
 }
@@ -113,10 +161,23 @@ func (s *Session) goRunList(ops []*operation, ginputs ...Data) ([]Data, error) {
 // safe run a func
 //
 func (s *Session) triggerRun(op *operation, ginputs ...Data) (Data, error) {
-	s.flow.hooks.start(op)
+	if s.trace != nil {
+		s.trace.start(op)
+	}
+	s.hooks().start(op)
 	var err error
 	var res Data
 
+	start := time.Now()
+	defer func() {
+		if s.quiet {
+			return
+		}
+		ready, _ := s.ready.Load(op)
+		t, _ := ready.(time.Time)
+		observeRun(op, start, t, err)
+	}()
+
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -129,19 +190,43 @@ func (s *Session) triggerRun(op *operation, ginputs ...Data) (Data, error) {
 				err = fmt.Errorf("%v %v", op, r)
 			}
 		}()
+		if rp := s.flow.replay; rp != nil {
+			if r, ok := rp.next(op); ok {
+				res, err = rp.result(op, r)
+				return
+			}
+		}
 		res, err = op.executor(s, ginputs...)
 	}()
+	if s.trace != nil {
+		s.trace.finish(op, err)
+	}
+	if s.flow.recorder != nil && !s.quiet {
+		inputs, _ := s.inputs.Load(op)
+		in, _ := inputs.([]Data)
+		s.flow.recorder.record(op, in, res, err, start)
+	}
 	if err != nil {
-		s.flow.hooks.error(op, err)
+		s.hooks().error(op, err)
 	} else {
-		s.flow.hooks.finish(op, res)
+		s.hooks().finish(op, res)
 	}
 	return res, err
 
 }
 func (s *Session) processInputs(op *operation, ginputs ...Data) ([]Data, error) {
-	s.flow.hooks.wait(op)
+	s.hooks().wait(op)
+	if s.trace != nil {
+		for _, in := range op.inputs {
+			s.trace.request(op, in)
+		}
+		defer s.trace.wait(op, time.Now())
+	}
 	res, err := s.goRunList(op.inputs, ginputs...)
-	s.flow.hooks.start(op) // Back to start
+	s.ready.Store(op, time.Now())
+	if s.flow.recorder != nil {
+		s.inputs.Store(op, res)
+	}
+	s.hooks().start(op) // Back to start
 	return res, err
 }
@@ -0,0 +1,179 @@
+package flow
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// Param a named flow input or output
+type Param struct {
+	Name    string
+	Type    reflect.Type // nil accepts any value
+	Default Data         // used when the input is not given, nil means required
+}
+
+func (p Param) String() string {
+	typ := "interface {}"
+	if p.Type != nil {
+		typ = p.Type.String()
+	}
+	if p.Default != nil {
+		return fmt.Sprintf("%s %s = %v", p.Name, typ, p.Default)
+	}
+	return fmt.Sprintf("%s %s", p.Name, typ)
+}
+
+// Signature declared named inputs and outputs of a flow
+type Signature struct {
+	Inputs  []Param
+	Outputs []Param
+}
+
+func (s Signature) String() string {
+	return fmt.Sprintf("%v -> %v", s.Inputs, s.Outputs)
+}
+
+type namedOutput struct {
+	Param
+	op *operation
+}
+
+// Input define a named input operation, if typ is not nil values are checked
+// against it, the same operation is returned for the same name if typ and
+// def are nil or match the declared ones
+func (f *Flow) Input(name string, typ reflect.Type, def Data) Operation {
+	for _, in := range f.inputs {
+		if in.name != name {
+			continue
+		}
+		if (typ != nil && typ != in.param.Type) || (def != nil && !reflect.DeepEqual(def, in.param.Default)) {
+			return f.ErrOp(fmt.Errorf("%v: '%s' redeclared as %v", ErrInput, name, Param{name, typ, def}))
+		}
+		return in
+	}
+	op := f.newOperation("input", nil)
+	op.name = name
+	op.param = &Param{name, typ, def}
+	op.executor = func(sess *Session, ginputs ...Data) (Data, error) {
+		v, ok := sess.named[name]
+		if !ok || v == nil {
+			if def == nil {
+				return nil, fmt.Errorf("%v: missing '%s'", ErrInput, name)
+			}
+			v = def
+		}
+		return checkParam(op.param, v)
+	}
+	f.inputs = append(f.inputs, op)
+	return op
+}
+
+// Output declares op as the named output of the flow, the output type is
+// the entry output, the input type or the const type, aliases left by
+// Optimize use the type of the operation they run
+func (f *Flow) Output(name string, op Operation) Operation {
+	o := op.(*operation)
+	typ := o.outputType()
+	for i, out := range f.outputs {
+		if out.Name == name {
+			f.outputs[i] = namedOutput{Param{Name: name, Type: typ}, o}
+			return op
+		}
+	}
+	f.outputs = append(f.outputs, namedOutput{Param{Name: name, Type: typ}, o})
+	return op
+}
+
+// Signature describes the named inputs and outputs of the flow
+func (f *Flow) Signature() Signature {
+	sig := Signature{}
+	for _, in := range f.inputs {
+		sig.Inputs = append(sig.Inputs, *in.param)
+	}
+	for _, out := range f.outputs {
+		sig.Outputs = append(sig.Outputs, out.Param)
+	}
+	return sig
+}
+
+// Outputs returns the operations of the named outputs in the same order
+// as the Signature outputs
+func (f *Flow) Outputs() []Operation {
+	ret := make([]Operation, len(f.outputs))
+	for i, out := range f.outputs {
+		ret[i] = out.op
+	}
+	return ret
+}
+
+// RunNamed runs every named output with the named inputs
+func (s *Session) RunNamed(inputs map[string]Data) (map[string]Data, error) {
+	for k := range inputs {
+		found := false
+		for _, in := range s.flow.inputs {
+			if in.name == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%v: unknown '%s'", ErrInput, k)
+		}
+	}
+	s.NamedInputs(inputs)
+
+	ops := make([]Operation, len(s.flow.outputs))
+	for i, out := range s.flow.outputs {
+		ops[i] = out.op
+	}
+	res, err := s.Run(ops...)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]Data{}
+	for i, out := range s.flow.outputs {
+		ret[out.Name] = res[i]
+	}
+	return ret, nil
+}
+
+// checkParam checks v against the param type, numeric values are converted
+func checkParam(p *Param, v Data) (Data, error) {
+	if p.Type == nil {
+		return v, nil
+	}
+	val := reflect.ValueOf(v)
+	if val.Type().AssignableTo(p.Type) {
+		return v, nil
+	}
+	if registry.IsNumber(val.Kind()) && registry.IsNumber(p.Type.Kind()) {
+		conv, err := registry.ConvertNumber(val, p.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%v: '%s' %v", ErrInput, p.Name, err)
+		}
+		return conv.Interface(), nil
+	}
+	return nil, fmt.Errorf("%v: '%s' expects %v got %T", ErrInput, p.Name, p.Type, v)
+}
+
+// outputType result type of the operation if known
+func (o *operation) outputType() reflect.Type {
+	for o.kind == "alias" {
+		o = o.inputs[0]
+	}
+	switch o.kind {
+	case "func":
+		if e, err := o.flow.registry.Entry(o.name); err == nil {
+			return e.Output
+		}
+	case "input":
+		return o.param.Type
+	case "const":
+		if v, _ := o.executor(nil); v != nil {
+			return reflect.TypeOf(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package flow_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+)
+
+func TestNamedInputs(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	x := f.Input("x", reflect.TypeOf(0), nil)
+	y := f.Input("y", reflect.TypeOf(0), 10)
+	a.Eq(f.Input("x", nil, nil), x, "same name should return the same input")
+
+	f.Output("sum", f.Op("add", x, y))
+	f.Output("x", x)
+
+	sig := f.Signature()
+	a.Eq(len(sig.Inputs), 2, "should have 2 inputs")
+	a.Eq(sig.Inputs[1].Default, 10, "should describe default")
+	a.Eq(sig.Outputs[0].Type, reflect.TypeOf(0), "output type should come from the entry")
+	t.Log(sig)
+
+	res, err := f.NewSession().RunNamed(map[string]flow.Data{"x": 1, "y": 2})
+	a.Eq(err, nil, "should not error")
+	a.Eq(res, map[string]flow.Data{"sum": 3, "x": 1}, "should return named outputs")
+
+	res, err = f.NewSession().RunNamed(map[string]flow.Data{"x": 1.0})
+	a.Eq(err, nil, "should use defaults and convert numbers")
+	a.Eq(res["sum"], 11, "should use default y")
+
+	_, err = f.NewSession().RunNamed(map[string]flow.Data{})
+	a.NotEq(err, nil, "should error on missing input")
+
+	_, err = f.NewSession().RunNamed(map[string]flow.Data{"x": "1"})
+	a.NotEq(err, nil, "should error on wrong type")
+
+	_, err = f.NewSession().RunNamed(map[string]flow.Data{"x": 1, "z": 1})
+	a.NotEq(err, nil, "should error on unknown input")
+}
+
+func TestNamedInputConvert(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	x := f.Input("x", reflect.TypeOf(0), nil)
+	b := f.Input("b", reflect.TypeOf(uint8(0)), 1)
+	f.Output("x", x)
+	f.Output("b", b)
+
+	res, err := f.NewSession().RunNamed(map[string]flow.Data{"x": 2.0, "b": 255})
+	a.Eq(err, nil, "integral values should convert")
+	a.Eq(res, map[string]flow.Data{"x": 2, "b": uint8(255)}, "should convert numbers")
+
+	_, err = f.NewSession().RunNamed(map[string]flow.Data{"x": 2.7})
+	a.NotEq(err, nil, "should not truncate 2.7")
+
+	_, err = f.NewSession().RunNamed(map[string]flow.Data{"x": 1, "b": 256})
+	a.NotEq(err, nil, "should not overflow uint8")
+
+	_, err = f.NewSession().RunNamed(map[string]flow.Data{"x": 1, "b": -1})
+	a.NotEq(err, nil, "should not convert negative to uint8")
+}
+
+func TestNamedInputRedeclare(t *testing.T) {
+	a := assert.A(t)
+	f := flow.New()
+
+	x := f.Input("x", reflect.TypeOf(0), 1)
+	a.Eq(f.Input("x", reflect.TypeOf(0), 1), x, "same declaration should return the same input")
+
+	_, err := f.Input("x", reflect.TypeOf(""), nil).Process()
+	a.NotEq(err, nil, "should error on a different type")
+
+	_, err = f.Input("x", nil, 2).Process()
+	a.NotEq(err, nil, "should error on a different default")
+}
+
+func TestNamedOutputs(t *testing.T) {
+	a := assert.A(t)
+	r := registry.New()
+	r.Add("add", func(a, b int) int { return a + b }).Pure()
+	f := flow.New().UseRegistry(r)
+
+	x := f.Input("x", reflect.TypeOf(0), nil)
+	sum := f.Op("add", x, 1)
+	dup := f.Op("add", x, 1)
+	f.Output("sum", sum)
+	f.Output("x", x)
+	a.Eq(f.Outputs(), []flow.Operation{sum, x}, "should return outputs in declaration order")
+
+	f.Optimize(sum, dup)
+	a.Eq(dup.Kind(), "alias", "duplicated op should be merged")
+	f.Output("dup", dup)
+	sig := f.Signature()
+	a.Eq(sig.Outputs[2].Type, reflect.TypeOf(0), "alias output type should come from the merged op")
+}
@@ -0,0 +1,141 @@
+package flow
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Span holds the timings of a single operation execution within a run
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string // span of the operation that requested this one
+
+	Name string // registry entry name or operation kind
+	Kind string
+	Tags []string
+	File string
+	Line int
+
+	Start time.Time
+	End   time.Time
+	Wait  time.Duration // time spent waiting for inputs
+	Exec  time.Duration // time spent executing
+	Err   error
+}
+
+// SpanExporter receives the spans of a finished Session.Run
+type SpanExporter interface {
+	Export(spans []*Span) error
+}
+
+// UseExporter traces every session run and sends the spans to e
+func (f *Flow) UseExporter(e SpanExporter) *Flow {
+	f.exporter = e
+	return f
+}
+
+// startTrace returns a copy of the session sharing its results with a new
+// trace if the flow has an exporter, so concurrent runs of a session have
+// their own trace, the returned func exports the collected spans
+func (s *Session) startTrace() (*Session, func()) {
+	if s.flow.exporter == nil {
+		return s, func() {}
+	}
+	t := newTrace()
+	rs := *s
+	rs.trace = t
+	return &rs, func() { t.export(s.flow.exporter) }
+}
+
+// trace collects spans of a single run
+type trace struct {
+	sync.Mutex
+	id      string
+	spans   map[*operation]*Span
+	parents map[*operation]*operation
+	order   []*Span
+}
+
+func newTrace() *trace {
+	return &trace{
+		id:      randHex(16),
+		spans:   map[*operation]*Span{},
+		parents: map[*operation]*operation{},
+	}
+}
+
+// request marks parent as the first requester of op
+func (t *trace) request(parent *operation, op *operation) {
+	t.Lock()
+	defer t.Unlock()
+	if _, ok := t.parents[op]; !ok {
+		t.parents[op] = parent
+	}
+}
+
+func (t *trace) start(op *operation) {
+	t.Lock()
+	defer t.Unlock()
+
+	span := &Span{
+		TraceID: t.id,
+		SpanID:  randHex(8),
+		Name:    op.name,
+		Kind:    op.kind,
+		File:    op.file,
+		Line:    op.line,
+		Tags:    op.tags(),
+		Start:   time.Now(),
+	}
+	if span.Name == "" {
+		span.Name = op.kind
+	}
+	if p, ok := t.spans[t.parents[op]]; ok {
+		span.ParentID = p.SpanID
+	}
+	t.spans[op] = span
+	t.order = append(t.order, span)
+}
+
+// wait adds the time since waitStart to the op waiting time
+func (t *trace) wait(op *operation, waitStart time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	if span, ok := t.spans[op]; ok {
+		span.Wait += time.Since(waitStart)
+	}
+}
+
+func (t *trace) finish(op *operation, err error) {
+	t.Lock()
+	defer t.Unlock()
+	span, ok := t.spans[op]
+	if !ok {
+		return
+	}
+	span.End = time.Now()
+	span.Exec = span.End.Sub(span.Start) - span.Wait
+	span.Err = err
+}
+
+func (t *trace) export(e SpanExporter) {
+	t.Lock()
+	defer t.Unlock()
+	if len(t.order) == 0 {
+		return
+	}
+	if err := e.Export(t.order); err != nil {
+		fmt.Fprintf(os.Stderr, "trace export: %v\n", err)
+	}
+}
+
+func randHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
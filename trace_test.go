@@ -0,0 +1,61 @@
+package flow_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/internal/assert"
+)
+
+type spanCollector struct {
+	spans []*flow.Span
+}
+
+func (c *spanCollector) Export(spans []*flow.Span) error {
+	c.spans = append(c.spans, spans...)
+	return nil
+}
+
+func TestTrace(t *testing.T) {
+	a := assert.A(t)
+	c := &spanCollector{}
+
+	f := flow.New()
+	f.UseExporter(c)
+
+	add := f.Op("vecadd", f.Op("vecmul", []float32{1, 2}, []float32{2, 2}), []float32{1, 1})
+	_, err := f.NewSession().Run(add)
+	a.Eq(err, nil, "run should not error")
+
+	byName := map[string]*flow.Span{}
+	for _, s := range c.spans {
+		byName[s.Name] = s
+		a.Eq(s.TraceID, c.spans[0].TraceID, "spans should belong to the same trace")
+		if s.Kind == "const" {
+			a.NotEq(s.ParentID, "", "const should have a parent")
+		}
+	}
+	a.Eq(byName["vecadd"].ParentID, "", "requested op should be a root span")
+	a.Eq(byName["vecmul"].ParentID, byName["vecadd"].SpanID, "input should be child of vecadd")
+	a.Eq(byName["vecadd"].Wait > 0, true, "vecadd should wait for inputs")
+}
+
+func TestOTLPFileExporter(t *testing.T) {
+	a := assert.A(t)
+	buf := bytes.NewBuffer(nil)
+
+	f := flow.New()
+	f.UseExporter(flow.NewOTLPFileExporter(buf))
+
+	_, err := f.Op("vecadd", []float32{1}, []float32{1}).Process()
+	a.Eq(err, nil, "process should not error")
+
+	req := map[string]interface{}{}
+	err = json.Unmarshal(buf.Bytes(), &req)
+	a.Eq(err, nil, "export should be valid json")
+
+	rs := req["resourceSpans"].([]interface{})
+	a.Eq(len(rs), 1, "should have one resource")
+}
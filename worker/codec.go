@@ -0,0 +1,265 @@
+package worker
+
+import (
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// Content types accepted by the worker server
+const (
+	JSON = "application/json"
+	Gob  = "application/x-gob"
+)
+
+type callRequest struct {
+	Entry string
+	Args  []interface{}
+}
+
+type callResponse struct {
+	Result interface{}
+	Err    string
+}
+
+// codec encodes calls, decoders receive the entry to restore value types
+type codec interface {
+	encodeRequest(w io.Writer, req callRequest) error
+	decodeRequest(r io.Reader, reg *registry.R) (callRequest, *registry.Entry, error)
+	encodeResponse(w io.Writer, res callResponse) error
+	decodeResponse(r io.Reader, e *registry.Entry) (callResponse, error)
+}
+
+func codecFor(contentType string) (codec, bool) {
+	switch contentType {
+	case JSON:
+		return jsonCodec{}, true
+	case Gob:
+		return gobCodec{}, true
+	}
+	return nil, false
+}
+
+//////////////////////
+// JSON codec, values are decoded into the entry reflected types
+////////
+
+type jsonCodec struct{}
+
+type jsonRequest struct {
+	Entry string            `json:"entry"`
+	Args  []json.RawMessage `json:"args"`
+}
+
+type jsonResponse struct {
+	Result json.RawMessage `json:"result"`
+	Err    string          `json:"error,omitempty"`
+}
+
+func (jsonCodec) encodeRequest(w io.Writer, req callRequest) error {
+	args := make([]interface{}, len(req.Args))
+	for i, a := range req.Args {
+		v, err := jsonWrap(a)
+		if err != nil {
+			return err
+		}
+		args[i] = v
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Entry string        `json:"entry"`
+		Args  []interface{} `json:"args"`
+	}{req.Entry, args})
+}
+
+func (jsonCodec) decodeRequest(r io.Reader, reg *registry.R) (callRequest, *registry.Entry, error) {
+	jr := jsonRequest{}
+	if err := json.NewDecoder(r).Decode(&jr); err != nil {
+		return callRequest{}, nil, err
+	}
+	e, err := reg.Entry(jr.Entry)
+	if err != nil {
+		return callRequest{}, nil, err
+	}
+	if len(jr.Args) != len(e.Inputs) {
+		return callRequest{}, nil, registry.ErrInput
+	}
+	req := callRequest{Entry: jr.Entry, Args: make([]interface{}, len(jr.Args))}
+	for i, raw := range jr.Args {
+		v, err := jsonValue(raw, e.Inputs[i])
+		if err != nil {
+			return callRequest{}, nil, err
+		}
+		req.Args[i] = v
+	}
+	return req, e, nil
+}
+
+func (jsonCodec) encodeResponse(w io.Writer, res callResponse) error {
+	result, err := jsonWrap(res.Result)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Result interface{} `json:"result"`
+		Err    string      `json:"error,omitempty"`
+	}{result, res.Err})
+}
+
+func (jsonCodec) decodeResponse(r io.Reader, e *registry.Entry) (callResponse, error) {
+	jr := jsonResponse{}
+	if err := json.NewDecoder(r).Decode(&jr); err != nil {
+		return callResponse{}, err
+	}
+	res, err := jsonValue(jr.Result, e.Output)
+	if err != nil {
+		return callResponse{}, err
+	}
+	return callResponse{res, jr.Err}, nil
+}
+
+func jsonValue(raw json.RawMessage, typ reflect.Type) (interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if tv, ok := jsonTyped(raw); ok {
+		return tv.decode()
+	}
+	if typ == nil {
+		var v interface{}
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+	if typ.Kind() == reflect.Interface && typ.NumMethod() > 0 {
+		return nil, fmt.Errorf("worker: can't decode %v, the concrete type must be registered with worker.Register", typ)
+	}
+	v := reflect.New(typ)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// typedValue JSON encoding of values of registered types, binary
+// marshalers are encoded as base64 strings
+type typedValue struct {
+	Type   string          `json:"@type"`
+	Value  json.RawMessage `json:"@value,omitempty"`
+	Binary []byte          `json:"@binary,omitempty"`
+}
+
+// jsonWrap wraps values of registered types to keep their type
+func jsonWrap(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	name := typeName(reflect.TypeOf(v))
+	if _, ok := types.Load(name); !ok {
+		return v, nil
+	}
+	tv := typedValue{Type: name}
+	var err error
+	if m, ok := v.(encoding.BinaryMarshaler); ok {
+		if _, isJSON := v.(json.Marshaler); !isJSON {
+			tv.Binary, err = m.MarshalBinary()
+			return tv, err
+		}
+	}
+	tv.Value, err = json.Marshal(v)
+	return tv, err
+}
+
+// jsonTyped decodes raw as a typedValue of a registered type
+func jsonTyped(raw json.RawMessage) (typedValue, bool) {
+	tv := typedValue{}
+	if raw[0] != '{' || json.Unmarshal(raw, &tv) != nil || tv.Type == "" {
+		return tv, false
+	}
+	_, ok := types.Load(tv.Type)
+	return tv, ok
+}
+
+func (tv typedValue) decode() (interface{}, error) {
+	t, _ := types.Load(tv.Type)
+	typ := t.(reflect.Type)
+	if tv.Binary != nil {
+		// pointer types unmarshal into a new pointed value
+		v := reflect.New(typ)
+		if typ.Kind() == reflect.Ptr {
+			v = reflect.New(typ.Elem())
+		}
+		u, ok := v.Interface().(encoding.BinaryUnmarshaler)
+		if !ok {
+			return nil, fmt.Errorf("worker: %s is not a binary unmarshaler", tv.Type)
+		}
+		if err := u.UnmarshalBinary(tv.Binary); err != nil {
+			return nil, err
+		}
+		if typ.Kind() == reflect.Ptr {
+			return v.Interface(), nil
+		}
+		return v.Elem().Interface(), nil
+	}
+	v := reflect.New(typ)
+	if err := json.Unmarshal(tv.Value, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+var types sync.Map // type name to reflect.Type
+
+// Register the concrete types of values sent behind interfaces, like
+// *mat.Dense for mat.Matrix inputs, for both codecs
+func Register(values ...interface{}) {
+	for _, v := range values {
+		gob.Register(v)
+		typ := reflect.TypeOf(v)
+		types.Store(typeName(typ), typ)
+	}
+}
+
+func typeName(typ reflect.Type) string {
+	if typ.Kind() == reflect.Ptr {
+		return "*" + typeName(typ.Elem())
+	}
+	if typ.PkgPath() != "" {
+		return typ.PkgPath() + "." + typ.Name()
+	}
+	return typ.String()
+}
+
+//////////////////////
+// Gob codec, concrete types behind interfaces must be registered with
+// Register or gob.Register
+////////
+
+type gobCodec struct{}
+
+func (gobCodec) encodeRequest(w io.Writer, req callRequest) error {
+	return gob.NewEncoder(w).Encode(req)
+}
+
+func (gobCodec) decodeRequest(r io.Reader, reg *registry.R) (callRequest, *registry.Entry, error) {
+	req := callRequest{}
+	if err := gob.NewDecoder(r).Decode(&req); err != nil {
+		return req, nil, err
+	}
+	e, err := reg.Entry(req.Entry)
+	return req, e, err
+}
+
+func (gobCodec) encodeResponse(w io.Writer, res callResponse) error {
+	return gob.NewEncoder(w).Encode(res)
+}
+
+func (gobCodec) decodeResponse(r io.Reader, e *registry.Entry) (callResponse, error) {
+	res := callResponse{}
+	err := gob.NewDecoder(r).Decode(&res)
+	return res, err
+}
@@ -0,0 +1,338 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// ErrNoWorker returned when no healthy worker can execute the entry
+var ErrNoWorker = errors.New("no worker available")
+
+// Worker a remote worker known by the pool
+type Worker struct {
+	Addr     string
+	healthy  bool
+	inflight int
+	entries  map[string]bool // nil while unknown
+
+	base   string
+	client *http.Client
+}
+
+// newWorker creates a worker for addr, addr can be an http url or
+// unix:///path/to/socket
+func newWorker(addr string) *Worker {
+	w := &Worker{Addr: addr, healthy: true, base: strings.TrimRight(addr, "/")}
+	transport := &http.Transport{}
+	if strings.HasPrefix(addr, "unix://") {
+		sock := strings.TrimPrefix(addr, "unix://")
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		}
+		w.base = "http://unix"
+	}
+	w.client = &http.Client{Transport: transport}
+	return w
+}
+
+func (w *Worker) has(name string) bool {
+	return w.entries == nil || w.entries[name]
+}
+
+// Pool dispatches remote entry calls to registered workers, it picks the
+// healthy worker with less calls in flight and fails over to the next when
+// the worker can't be reached or answers with a server error, timeouts and
+// requests rejected by a worker are returned to the caller
+type Pool struct {
+	sync.Mutex
+	workers []*Worker
+	next    int
+	// ContentType used to encode calls, JSON or Gob
+	ContentType string
+	// Timeout of each call to a worker, 0 waits forever
+	Timeout time.Duration
+	done    chan struct{}
+}
+
+// DefaultTimeout of calls made by pools created with NewPool
+const DefaultTimeout = time.Minute
+
+// NewPool creates a pool with the worker addresses
+func NewPool(addrs ...string) *Pool {
+	p := &Pool{ContentType: JSON, Timeout: DefaultTimeout}
+	for _, a := range addrs {
+		p.Register(a)
+	}
+	return p
+}
+
+// Register adds a worker to the pool
+func (p *Pool) Register(addr string) {
+	p.Lock()
+	defer p.Unlock()
+	for _, w := range p.workers {
+		if w.Addr == addr {
+			w.healthy = true
+			return
+		}
+	}
+	p.workers = append(p.workers, newWorker(addr))
+}
+
+// Unregister removes a worker from the pool
+func (p *Pool) Unregister(addr string) {
+	p.Lock()
+	defer p.Unlock()
+	for i, w := range p.workers {
+		if w.Addr == addr {
+			p.workers = append(p.workers[:i], p.workers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Workers returns the addresses of healthy workers
+func (p *Pool) Workers() []string {
+	p.Lock()
+	defer p.Unlock()
+	ret := []string{}
+	for _, w := range p.workers {
+		if w.healthy {
+			ret = append(ret, w.Addr)
+		}
+	}
+	return ret
+}
+
+// pick a healthy worker not yet tried for entry name
+func (p *Pool) pick(name string, tried map[*Worker]bool) *Worker {
+	p.Lock()
+	defer p.Unlock()
+
+	var best *Worker
+	n := len(p.workers)
+	for i := 0; i < n; i++ {
+		w := p.workers[(p.next+i)%n]
+		if !w.healthy || tried[w] || !w.has(name) {
+			continue
+		}
+		if best == nil || w.inflight < best.inflight {
+			best = w
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	p.next = (p.next + 1) % n
+	best.inflight++
+	return best
+}
+
+func (p *Pool) release(w *Worker, healthy bool) {
+	p.Lock()
+	defer p.Unlock()
+	w.inflight--
+	if !healthy {
+		w.healthy = false
+	}
+}
+
+// Call implements registry.Dispatcher
+func (p *Pool) Call(name string, e *registry.Entry, params ...interface{}) (interface{}, error) {
+	c, ok := codecFor(p.ContentType)
+	if !ok {
+		return nil, fmt.Errorf("worker: unsupported content type %q", p.ContentType)
+	}
+	body := bytes.NewBuffer(nil)
+	if err := c.encodeRequest(body, callRequest{name, params}); err != nil {
+		return nil, err
+	}
+
+	tried := map[*Worker]bool{}
+	for {
+		w := p.pick(name, tried)
+		if w == nil {
+			return nil, ErrNoWorker
+		}
+		tried[w] = true
+
+		res, failover, err := p.send(w, c, bytes.NewReader(body.Bytes()), e)
+		p.release(w, !failover)
+		if failover {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if res.Err != "" {
+			return nil, errors.New(res.Err)
+		}
+		return res.Result, nil
+	}
+}
+
+// send the call to w, failover is true if the worker is unreachable or
+// failed, other errors are caused by the call itself. Errors after the
+// connection is made might come from a call already running on the worker
+// so they are not retried
+func (p *Pool) send(w *Worker, c codec, body *bytes.Reader, e *registry.Entry) (res callResponse, failover bool, err error) {
+	ctx := context.Background()
+	if p.Timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequest(http.MethodPost, w.base+"/call", body)
+	if err != nil {
+		return res, false, err
+	}
+	req.Header.Set("Content-Type", p.ContentType)
+	hres, err := w.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return res, unreachable(err), err
+	}
+	defer hres.Body.Close()
+	if hres.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(hres.Body)
+		err = fmt.Errorf("worker %s: %s", w.Addr, bytes.TrimSpace(msg))
+		return res, hres.StatusCode >= http.StatusInternalServerError, err
+	}
+	res, err = c.decodeResponse(hres.Body, e)
+	return res, false, err
+}
+
+// unreachable reports if err happened while connecting to the worker
+func unreachable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// HealthCheck checks every worker once, updating their state and entries
+func (p *Pool) HealthCheck() {
+	p.Lock()
+	workers := append([]*Worker{}, p.workers...)
+	p.Unlock()
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(workers))
+	for _, w := range workers {
+		go func(w *Worker) {
+			defer wg.Done()
+			entries, err := checkHealth(w)
+
+			p.Lock()
+			defer p.Unlock()
+			w.healthy = err == nil
+			if err == nil {
+				w.entries = entries
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func checkHealth(w *Worker) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, w.base+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := w.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("worker %s: health status %d", w.Addr, res.StatusCode)
+	}
+	h := healthResponse{}
+	if err := json.NewDecoder(res.Body).Decode(&h); err != nil {
+		return nil, err
+	}
+	entries := map[string]bool{}
+	for _, e := range h.Entries {
+		entries[e] = true
+	}
+	return entries, nil
+}
+
+// Start runs health checks every interval until Close
+func (p *Pool) Start(interval time.Duration) {
+	p.Lock()
+	if p.done != nil {
+		p.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	p.done = done
+	p.Unlock()
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			p.HealthCheck()
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// Close stops health checks
+func (p *Pool) Close() {
+	p.Lock()
+	defer p.Unlock()
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+}
+
+// ServeHTTP handles worker registration, workers POST their address to
+// register and DELETE to leave the pool
+func (p *Pool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	addr, err := ioutil.ReadAll(r.Body)
+	if err != nil || len(addr) == 0 {
+		http.Error(w, "invalid worker address", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		p.Register(string(addr))
+	case http.MethodDelete:
+		p.Unregister(string(addr))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Announce registers the worker addr in the pool served at poolURL
+func Announce(poolURL, addr string) error {
+	res, err := http.Post(poolURL, "text/plain", strings.NewReader(addr))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("worker: announce status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package worker executes registry entries in other processes
+//
+// A worker process serves a registry with NewServer, the flow process marks
+// entries as Remote and dispatches their calls through a Pool:
+//
+//	r := ml.New()
+//	r.Add(...).Remote()
+//	r.UseDispatcher(worker.NewPool("http://localhost:2016", "unix:///tmp/worker.sock"))
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hexasoftware/flow/registry"
+)
+
+// Server executes registry entries on behalf of a Pool
+type Server struct {
+	registry *registry.R
+}
+
+// NewServer creates a worker server for registry r
+func NewServer(r *registry.R) *Server {
+	return &Server{registry: r}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/health":
+		s.health(w, r)
+	case "/call":
+		s.call(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// health replies with the entries this worker is able to execute, every
+// version is listed as name@N besides the plain name
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	desc, err := s.registry.Descriptions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	entries := make([]string, 0, len(desc))
+	for k := range desc {
+		entries = append(entries, k)
+		for _, v := range s.registry.Versions(k) {
+			entries = append(entries, fmt.Sprintf("%s@%d", k, v))
+		}
+	}
+	sort.Strings(entries)
+	w.Header().Set("Content-Type", JSON)
+	json.NewEncoder(w).Encode(healthResponse{entries})
+}
+
+func (s *Server) call(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = JSON
+	}
+	c, ok := codecFor(contentType)
+	if !ok {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	req, e, err := c.decodeRequest(r.Body, s.registry)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := callResponse{}
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				res = callResponse{Err: fmt.Sprint(p)}
+			}
+		}()
+		res.Result, err = e.Call(req.Args...)
+		if err != nil {
+			res.Err = err.Error()
+		}
+	}()
+
+	w.Header().Set("Content-Type", contentType)
+	c.encodeResponse(w, res)
+}
+
+type healthResponse struct {
+	Entries []string `json:"entries"`
+}
@@ -0,0 +1,210 @@
+package worker_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hexasoftware/flow"
+	"github.com/hexasoftware/flow/example/demos/ops/ml"
+	"github.com/hexasoftware/flow/internal/assert"
+	"github.com/hexasoftware/flow/registry"
+	"github.com/hexasoftware/flow/worker"
+	"gonum.org/v1/gonum/mat"
+)
+
+// startUnixWorker starts a worker serving r on a unix socket
+func startUnixWorker(t *testing.T, r *registry.R) (string, func()) {
+	dir, err := os.MkdirTemp("", "worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sock := filepath.Join(dir, "worker.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: worker.NewServer(r)}
+	go srv.Serve(l)
+	return "unix://" + sock, func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRemoteCall(t *testing.T) {
+	a := assert.A(t)
+	wr := registry.New()
+	wr.Add("add", func(a, b int) int { return a + b }).Remote()
+	wr.Add("join", func(a []string) string { return strings.Join(a, "") }).Remote()
+	wr.Add("fail", func() (int, error) { return 0, errors.New("failed") }).Remote()
+	w := httptest.NewServer(worker.NewServer(wr))
+	defer w.Close()
+
+	r := wr.Clone().UseDispatcher(worker.NewPool(w.URL))
+
+	f := flow.New().UseRegistry(r)
+	res, err := f.Op("add", 1, f.Op("add", 2, 3)).Process()
+	a.Eq(err, nil, "remote call should not error")
+	a.Eq(res, 6, "remote result should be decoded with the entry type")
+
+	res, err = f.Op("join", []string{"a", "b"}).Process()
+	a.Eq(err, nil, "remote call should not error")
+	a.Eq(res, "ab", "should join")
+
+	_, err = f.Op("fail").Process()
+	a.NotEq(err, nil, "remote entry error should be returned")
+}
+
+func TestRemoteUnixGob(t *testing.T) {
+	a := assert.A(t)
+	wr := registry.New()
+	wr.Add("add", func(a, b int) int { return a + b }).Remote()
+	addr, stop := startUnixWorker(t, wr)
+	defer stop()
+
+	pool := worker.NewPool(addr)
+	pool.ContentType = worker.Gob
+	r := wr.Clone().UseDispatcher(pool)
+
+	res, err := flow.New().UseRegistry(r).Op("add", 4, 4).Process()
+	a.Eq(err, nil, "unix socket call should not error")
+	a.Eq(res, 8, "should add")
+}
+
+func TestFailover(t *testing.T) {
+	a := assert.A(t)
+	wr := registry.New()
+	wr.Add("add", func(a, b int) int { return a + b }).Remote()
+	down := httptest.NewServer(worker.NewServer(wr))
+	down.Close()
+	up := httptest.NewServer(worker.NewServer(wr))
+	defer up.Close()
+
+	pool := worker.NewPool(down.URL, up.URL)
+	r := wr.Clone().UseDispatcher(pool)
+
+	for i := 0; i < 4; i++ {
+		res, err := flow.New().UseRegistry(r).Op("add", i, 1).Process()
+		a.Eq(err, nil, "should failover to a working worker")
+		a.Eq(res, i+1, "should add")
+	}
+	a.Eq(pool.Workers(), []string{up.URL}, "down worker should be marked unhealthy")
+
+	up.Close()
+	_, err := flow.New().UseRegistry(r).Op("add", 1, 1).Process()
+	a.NotEq(err, nil, "should error without workers")
+}
+
+func TestHealthCheck(t *testing.T) {
+	a := assert.A(t)
+	w := httptest.NewServer(worker.NewServer(registry.New()))
+	defer w.Close()
+
+	pool := worker.NewPool(w.URL)
+	pool.HealthCheck()
+	a.Eq(pool.Workers(), []string{w.URL}, "worker should be healthy")
+
+	_, err := pool.Call("add", nil, 1, 2)
+	a.Eq(err, worker.ErrNoWorker, "worker without the entry should not be used")
+
+	wr := registry.New()
+	wr.Add("add", func(a, b int) int { return a + b })
+	wr.Add("add", func(a, b int) int { return a + b + 1 }).Version(2)
+	vw := httptest.NewServer(worker.NewServer(wr))
+	defer vw.Close()
+	pool = worker.NewPool(vw.URL)
+	pool.HealthCheck()
+	e, err := wr.Entry("add@2")
+	a.Eq(err, nil, "should find the versioned entry")
+	res, err := pool.Call("add@2", e, 1, 2)
+	a.Eq(err, nil, "worker should list versioned entries")
+	a.Eq(res, 4, "should call the requested version")
+}
+
+func TestAnnounce(t *testing.T) {
+	a := assert.A(t)
+	pool := worker.NewPool()
+	ps := httptest.NewServer(pool)
+	defer ps.Close()
+
+	err := worker.Announce(ps.URL, "http://localhost:1")
+	a.Eq(err, nil, "announce should not error")
+	a.Eq(pool.Workers(), []string{"http://localhost:1"}, "worker should be registered")
+}
+
+func TestRejectedCall(t *testing.T) {
+	a := assert.A(t)
+	w1 := httptest.NewServer(worker.NewServer(registry.New()))
+	defer w1.Close()
+	w2 := httptest.NewServer(worker.NewServer(registry.New()))
+	defer w2.Close()
+
+	pool := worker.NewPool(w1.URL, w2.URL)
+	r := registry.New().UseDispatcher(pool)
+	r.Add("add", func(a, b int) int { return a + b }).Remote()
+	_, err := flow.New().UseRegistry(r).Op("add", 1, 1).Process()
+	a.NotEq(err, nil, "rejected call should error")
+	a.Eq(len(pool.Workers()), 2, "rejecting workers should stay healthy")
+}
+
+func TestTimeout(t *testing.T) {
+	a := assert.A(t)
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer hung.Close()
+	wr := registry.New()
+	wr.Add("add", func(a, b int) int { return a + b }).Remote()
+	up := httptest.NewServer(worker.NewServer(wr))
+	defer up.Close()
+
+	pool := worker.NewPool(hung.URL, up.URL)
+	pool.Timeout = 50 * time.Millisecond
+	r := wr.Clone().UseDispatcher(pool)
+	failed := 0
+	for i := 0; i < 2; i++ {
+		res, err := flow.New().UseRegistry(r).Op("add", i, 1).Process()
+		if err != nil {
+			failed++
+			continue
+		}
+		a.Eq(res, i+1, "should add")
+	}
+	a.Eq(failed, 1, "timed out call should error instead of failing over")
+	a.Eq(pool.Workers(), []string{hung.URL, up.URL}, "hung worker should stay healthy")
+}
+
+func TestRemoteMatrix(t *testing.T) {
+	a := assert.A(t)
+	w := httptest.NewServer(worker.NewServer(ml.New()))
+	defer w.Close()
+
+	m1 := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+	m2 := mat.NewDense(2, 2, []float64{5, 6, 7, 8})
+	want := &mat.Dense{}
+	want.Mul(m1, m2)
+
+	for _, ct := range []string{worker.JSON, worker.Gob} {
+		pool := worker.NewPool(w.URL)
+		pool.ContentType = ct
+		r := ml.New().UseDispatcher(pool)
+		e, err := r.Entry("matMul")
+		a.Eq(err, nil, "should find the ml entry")
+		e.Describer().Remote()
+
+		res, err := flow.New().UseRegistry(r).Op("matMul", m1, m2).Process()
+		a.Eq(err, nil, ct+" matrix call should not error")
+		m, ok := res.(mat.Matrix)
+		a.Eq(ok, true, ct+" result should be a matrix")
+		if ok {
+			a.Eq(mat.Equal(m, want), true, ct+" should multiply remotely")
+		}
+	}
+}